@@ -0,0 +1,702 @@
+package gocomplain
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/token"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gordonklaus/ineffassign/pkg/ineffassign"
+	hl "github.com/mjwhitta/hilighter"
+	"github.com/mjwhitta/log"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/appends"
+	"golang.org/x/tools/go/analysis/passes/asmdecl"
+	"golang.org/x/tools/go/analysis/passes/assign"
+	"golang.org/x/tools/go/analysis/passes/atomic"
+	"golang.org/x/tools/go/analysis/passes/bools"
+	"golang.org/x/tools/go/analysis/passes/buildtag"
+	"golang.org/x/tools/go/analysis/passes/cgocall"
+	"golang.org/x/tools/go/analysis/passes/composite"
+	"golang.org/x/tools/go/analysis/passes/copylock"
+	"golang.org/x/tools/go/analysis/passes/defers"
+	"golang.org/x/tools/go/analysis/passes/directive"
+	"golang.org/x/tools/go/analysis/passes/errorsas"
+	"golang.org/x/tools/go/analysis/passes/framepointer"
+	"golang.org/x/tools/go/analysis/passes/httpresponse"
+	"golang.org/x/tools/go/analysis/passes/ifaceassert"
+	"golang.org/x/tools/go/analysis/passes/loopclosure"
+	"golang.org/x/tools/go/analysis/passes/lostcancel"
+	"golang.org/x/tools/go/analysis/passes/nilfunc"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/analysis/passes/shift"
+	"golang.org/x/tools/go/analysis/passes/sigchanyzer"
+	"golang.org/x/tools/go/analysis/passes/slog"
+	"golang.org/x/tools/go/analysis/passes/stdmethods"
+	"golang.org/x/tools/go/analysis/passes/stdversion"
+	"golang.org/x/tools/go/analysis/passes/stringintconv"
+	"golang.org/x/tools/go/analysis/passes/structtag"
+	"golang.org/x/tools/go/analysis/passes/testinggoroutine"
+	"golang.org/x/tools/go/analysis/passes/tests"
+	"golang.org/x/tools/go/analysis/passes/timeformat"
+	"golang.org/x/tools/go/analysis/passes/unmarshal"
+	"golang.org/x/tools/go/analysis/passes/unreachable"
+	"golang.org/x/tools/go/analysis/passes/unsafeptr"
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
+	"golang.org/x/tools/go/analysis/unitchecker"
+	"golang.org/x/tools/go/packages"
+	"honnef.co/go/tools/quickfix"
+	"honnef.co/go/tools/simple"
+	"honnef.co/go/tools/staticcheck"
+	"honnef.co/go/tools/stylecheck"
+	"honnef.co/go/tools/unused"
+)
+
+// analyzeDiag is a diagnostic, as printed by "go vet -json".
+type analyzeDiag struct {
+	Message string `json:"message"`
+	Posn    string `json:"posn"`
+}
+
+// The environment variable set when gocomplain is run by "go vet", as
+// its -vettool
+var analyzeEnv string = "GOCOMPLAIN_VETTOOL"
+
+// Analyzers already wrapped by guard
+var guarded map[*analysis.Analyzer]bool
+var guardedMutex sync.Mutex
+
+// unusedAnalyzer will report the unused code found by staticcheck.
+// Unlike other checks, these are results rather than diagnostics.
+var unusedAnalyzer *analysis.Analyzer = &analysis.Analyzer{
+	Doc:      unused.Analyzer.Analyzer.Doc,
+	Name:     unused.Analyzer.Analyzer.Name,
+	Requires: []*analysis.Analyzer{unused.Analyzer.Analyzer},
+	Run:      analyzeUnused,
+}
+
+// The passes run by "go vet"
+var vetAnalyzers []*analysis.Analyzer = []*analysis.Analyzer{
+	appends.Analyzer,
+	asmdecl.Analyzer,
+	assign.Analyzer,
+	atomic.Analyzer,
+	bools.Analyzer,
+	buildtag.Analyzer,
+	cgocall.Analyzer,
+	composite.Analyzer,
+	copylock.Analyzer,
+	defers.Analyzer,
+	directive.Analyzer,
+	errorsas.Analyzer,
+	framepointer.Analyzer,
+	httpresponse.Analyzer,
+	ifaceassert.Analyzer,
+	loopclosure.Analyzer,
+	lostcancel.Analyzer,
+	nilfunc.Analyzer,
+	printf.Analyzer,
+	shift.Analyzer,
+	sigchanyzer.Analyzer,
+	slog.Analyzer,
+	stdmethods.Analyzer,
+	stdversion.Analyzer,
+	stringintconv.Analyzer,
+	structtag.Analyzer,
+	testinggoroutine.Analyzer,
+	tests.Analyzer,
+	timeformat.Analyzer,
+	unmarshal.Analyzer,
+	unreachable.Analyzer,
+	unsafeptr.Analyzer,
+	unusedresult.Analyzer,
+}
+
+// Analyze will run the analyzers for each of the provided tools
+// (govet, ineffassign, staticcheck) together, using "go vet" with
+// gocomplain as its -vettool, so dependencies are loaded from export
+// data and their facts are cached by the go command. The current
+// executable must call AnalyzerMain at the start of main. If no files
+// are provided, all packages in the current module are analyzed.
+// Files in the same directory are analyzed together, so tests are
+// loaded with the package they test. Findings for unchanged packages
+// are reused, if Cache is enabled.
+func Analyze(tools []string, src ...map[string][]string) []Finding {
+	var dirs map[string][]string = map[string][]string{}
+	var out []Finding
+
+	if len(src) == 0 {
+		return analyzePkgs(tools, "./...")
+	}
+
+	for i := range src {
+		for dir, files := range src[i] {
+			dirs[dir] = append(dirs[dir], files...)
+		}
+	}
+
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		out = append(out, analyzeDir(tools, dir, dirs[dir])...)
+	}
+
+	return out
+}
+
+// AnalyzerMain will run the analyzers for a single package, and exit,
+// if the process was started by Analyze as the -vettool for "go
+// vet". Otherwise it returns immediately.
+func AnalyzerMain() {
+	var all []*analysis.Analyzer
+	var tools []string = []string{
+		"govet", "ineffassign", "staticcheck",
+	}
+
+	if os.Getenv(analyzeEnv) == "" {
+		return
+	}
+
+	for _, tool := range tools {
+		all = append(all, analyzers(tool)...)
+	}
+
+	guard(all)
+
+	// Only the analyzer flags, not those of the calling program
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	unitchecker.Main(all...)
+}
+
+// analyze will run "go vet", with gocomplain as its -vettool, on the
+// packages matching the provided patterns and return the findings of
+// the analyzers for the provided tools, by the ID of the package they
+// were found in. Errors outside of a package use an empty ID.
+func analyze(
+	tools []string, patterns ...string,
+) map[string][]Finding {
+	var args []string
+	var b []byte
+	var cmd *exec.Cmd
+	var e error
+	var exe string
+	var owner map[string]string = map[string]string{}
+
+	for _, tool := range tools {
+		for _, a := range analyzers(tool) {
+			args = append(args, "-"+a.Name)
+			owner[a.Name] = tool
+		}
+	}
+
+	if (len(args) == 0) || (len(patterns) == 0) {
+		return nil
+	}
+
+	if exe, e = os.Executable(); e != nil {
+		return map[string][]Finding{
+			"": {
+				{
+					Msg:  "failed to find executable: " + e.Error(),
+					Tool: tools[0],
+				},
+			},
+		}
+	}
+
+	if Debug {
+		log.Debugf("go vet %s", strings.Join(patterns, " "))
+	}
+
+	args = slices.Concat(
+		[]string{"vet", "-vettool=" + exe, "-json"},
+		args,
+		patterns,
+	)
+
+	cmd = exec.Command("go", args...)
+	cmd.Env = append(os.Environ(), analyzeEnv+"=1")
+
+	// Diagnostics are JSON, anything else is an error
+	if b, e = cmd.CombinedOutput(); (e != nil) && (len(b) == 0) {
+		return map[string][]Finding{
+			"": {
+				{
+					Msg:  "failed to run go vet: " + e.Error(),
+					Tool: tools[0],
+				},
+			},
+		}
+	}
+
+	return analyzeOutput(tools[0], owner, exe, b)
+}
+
+func analyzeCmd(tools []string) []string {
+	return slices.Concat(
+		[]string{"gocomplain", "analyze", Version},
+		tools,
+	)
+}
+
+func analyzeDir(
+	tools []string, dir string, files []string,
+) []Finding {
+	var paths []string
+
+	for _, file := range files {
+		paths = append(paths, filepath.Join(dir, file))
+	}
+
+	return analyzePkgs(tools, analyzePatterns(dir, paths)...)
+}
+
+// analyzeErr will convert a package load or type error, printed by
+// "go vet", to a Finding.
+func analyzeErr(tool string, ln string) Finding {
+	var f Finding = ParseFinding(tool, ln)
+
+	f.File = relPath(f.File)
+
+	return f
+}
+
+// analyzeFinding will return a Finding for the provided diagnostic.
+// Like the staticcheck command, checks are identified by name.
+func analyzeFinding(
+	tool string, check string, d analyzeDiag,
+) Finding {
+	var f Finding = ParseFinding(tool, d.Posn+": "+d.Message)
+
+	if tool == "staticcheck" {
+		f.Msg = hl.Sprintf("%s (%s)", f.Msg, check)
+	}
+
+	f.File = relPath(f.File)
+
+	return f
+}
+
+// analyzeFlat will return the provided findings, in package order,
+// without duplicates.
+func analyzeFlat(found map[string][]Finding) []Finding {
+	var out []Finding
+
+	for _, id := range slices.Sorted(maps.Keys(found)) {
+		out = append(out, found[id]...)
+	}
+
+	return dedupe(out)
+}
+
+func analyzeGet(key string) ([]Finding, bool) {
+	var f Finding
+	var found []Finding
+	var lines []string
+	var ok bool
+
+	if lines, ok = cacheGet(key); !ok {
+		return nil, false
+	}
+
+	for _, ln := range lines {
+		f = Finding{}
+
+		if json.Unmarshal([]byte(ln), &f) != nil {
+			return nil, false
+		}
+
+		found = append(found, f)
+	}
+
+	return found, true
+}
+
+// analyzeJSON will add the findings in the provided JSON output of
+// "go vet", which maps each package to the diagnostics, or error, of
+// each analyzer, to those of each package.
+func analyzeJSON(
+	found map[string][]Finding, owner map[string]string, b []byte,
+) {
+	var diags []analyzeDiag
+	var failed struct {
+		Err string `json:"error"`
+	}
+	var tree map[string]map[string]json.RawMessage
+
+	if json.Unmarshal(b, &tree) != nil {
+		return
+	}
+
+	for _, pkg := range slices.Sorted(maps.Keys(tree)) {
+		for _, name := range slices.Sorted(maps.Keys(tree[pkg])) {
+			diags = nil
+
+			if json.Unmarshal(tree[pkg][name], &diags) != nil {
+				_ = json.Unmarshal(tree[pkg][name], &failed)
+				found[pkg] = append(
+					found[pkg],
+					Finding{
+						Msg: hl.Sprintf(
+							"%s failed on %s: %s",
+							name,
+							pkg,
+							failed.Err,
+						),
+						Tool: owner[name],
+					},
+				)
+
+				continue
+			}
+
+			for _, d := range diags {
+				found[pkg] = append(
+					found[pkg],
+					analyzeFinding(owner[name], name, d),
+				)
+			}
+		}
+	}
+}
+
+// analyzeLines will return the findings of the provided tool as
+// lines of output, like those of the tool's own command.
+func analyzeLines(tool string, src ...map[string][]string) []string {
+	var out []string
+
+	for _, f := range Analyze([]string{tool}, src...) {
+		out = append(out, f.String())
+	}
+
+	return out
+}
+
+// analyzeMisses will analyze the provided patterns, and add the
+// findings to those of the directory, of those provided, owning the
+// package or file they were found in. Any findings which can't be
+// attributed are returned, so nothing is cached.
+func analyzeMisses(
+	tools []string,
+	patterns []string,
+	dirs map[string][]*packages.Package,
+	misses []string,
+	found map[string][]Finding,
+) []Finding {
+	var abs string
+	var dir string
+	var ok bool
+	var owner map[string]string = map[string]string{}
+	var results map[string][]Finding
+	var unknown []Finding
+
+	for _, dir := range misses {
+		for _, pkg := range dirs[dir] {
+			owner[pkg.ID] = dir
+
+			for _, fn := range pkg.GoFiles {
+				owner[fn] = dir
+			}
+
+			for _, fn := range pkg.OtherFiles {
+				owner[fn] = dir
+			}
+		}
+	}
+
+	results = analyze(tools, patterns...)
+
+	for _, id := range slices.Sorted(maps.Keys(results)) {
+		for _, f := range results[id] {
+			if dir, ok = owner[id]; !ok {
+				abs, _ = filepath.Abs(f.File)
+				dir, ok = owner[abs]
+			}
+
+			if !ok {
+				unknown = append(unknown, f)
+				continue
+			}
+
+			found[dir] = append(found[dir], f)
+		}
+	}
+
+	for _, dir := range misses {
+		found[dir] = dedupe(found[dir])
+	}
+
+	return dedupe(unknown)
+}
+
+// analyzeOutput will return the findings in the provided output of
+// "go vet -json". Diagnostics are printed as JSON, after a "# pkg"
+// comment, and anything else is a load or type error, possibly
+// prefixed with the name of the -vettool.
+func analyzeOutput(
+	tool string, owner map[string]string, exe string, b []byte,
+) map[string][]Finding {
+	var block []string
+	var found map[string][]Finding = map[string][]Finding{}
+	var prefix string = filepath.Base(exe) + ": "
+
+	for _, ln := range strings.Split(string(b), "\n") {
+		switch {
+		case (len(block) > 0) || (ln == "{"):
+			if block = append(block, ln); ln != "}" {
+				continue
+			}
+
+			ln = strings.Join(block, "\n")
+			analyzeJSON(found, owner, []byte(ln))
+			block = nil
+		case strings.HasPrefix(ln, "#"), strings.TrimSpace(ln) == "":
+		default:
+			ln = strings.TrimPrefix(ln, prefix)
+			ln = strings.TrimPrefix(ln, "vet: ")
+			found[""] = append(found[""], analyzeErr(tool, ln))
+		}
+	}
+
+	return found
+}
+
+// analyzePatterns will return the patterns for "go vet" to analyze
+// the provided directory. Within a module, the package in that
+// directory is analyzed, otherwise its files are.
+func analyzePatterns(dir string, paths []string) []string {
+	if cacheModule() == "" {
+		return paths
+	} else if filepath.IsAbs(dir) || (dir == ".") {
+		return []string{dir}
+	}
+
+	return []string{"./" + filepath.ToSlash(dir)}
+}
+
+// analyzePkgs will analyze the packages matching the provided
+// patterns. If Cache is enabled, findings are cached by the directory
+// of the package they were found in, and reused while none of the
+// files it depends on change.
+func analyzePkgs(tools []string, patterns ...string) []Finding {
+	var dirs map[string][]*packages.Package
+	var e error
+	var found map[string][]Finding = map[string][]Finding{}
+	var keys map[string]string = map[string]string{}
+	var misses []string
+	var ok bool
+	var out []Finding
+	var unknown []Finding
+
+	if !Cache {
+		return analyzeFlat(analyze(tools, patterns...))
+	}
+
+	// Without keys, nothing can be cached
+	if dirs, e = cacheLoad(patterns...); e != nil {
+		return analyzeFlat(analyze(tools, patterns...))
+	}
+
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		keys[dir] = cacheKey(
+			analyzeCmd(tools),
+			cacheFiles(dirs[dir])...,
+		)
+
+		if found[dir], ok = analyzeGet(keys[dir]); !ok {
+			misses = append(misses, dir)
+		}
+	}
+
+	// Only changed packages are analyzed again
+	if len(misses) < len(dirs) {
+		patterns = misses
+	}
+
+	if len(misses) > 0 {
+		unknown = analyzeMisses(tools, patterns, dirs, misses, found)
+	}
+
+	// Nothing is cached unless every finding has an owner
+	if len(unknown) == 0 {
+		for _, dir := range misses {
+			analyzePut(keys[dir], found[dir])
+		}
+	}
+
+	out = unknown
+
+	for _, dir := range slices.Sorted(maps.Keys(dirs)) {
+		out = append(out, found[dir]...)
+	}
+
+	return out
+}
+
+func analyzePut(key string, found []Finding) {
+	var b []byte
+	var e error
+	var lines []string
+
+	for _, f := range found {
+		if b, e = json.Marshal(f); e != nil {
+			return
+		}
+
+		lines = append(lines, string(b))
+	}
+
+	cachePut(key, lines)
+}
+
+// analyzeUnused will report the unused code found by staticcheck.
+// As "go vet" only analyzes the test variant of a package with tests,
+// code used by tests isn't reported.
+func analyzeUnused(pass *analysis.Pass) (any, error) {
+	var files map[string]*token.File = map[string]*token.File{}
+	var res unused.Result
+	var tf *token.File
+
+	res, _ = pass.ResultOf[unused.Analyzer.Analyzer].(unused.Result)
+
+	for _, f := range pass.Files {
+		tf = pass.Fset.File(f.Pos())
+		files[tf.Name()] = tf
+	}
+
+	for _, obj := range res.Unused {
+		if tf = files[obj.Position.Filename]; tf == nil {
+			continue
+		}
+
+		pass.Reportf(
+			tf.Pos(obj.Position.Offset),
+			"%s %s is unused",
+			obj.Kind,
+			obj.Name,
+		)
+	}
+
+	return nil, nil
+}
+
+// analyzers will return the analyzers run in process for the
+// provided tool.
+func analyzers(tool string) []*analysis.Analyzer {
+	var out []*analysis.Analyzer
+
+	switch tool {
+	case "govet":
+		return vetAnalyzers
+	case "ineffassign":
+		return []*analysis.Analyzer{ineffassign.Analyzer}
+	case "staticcheck":
+		for _, a := range slices.Concat(
+			quickfix.Analyzers,
+			simple.Analyzers,
+			staticcheck.Analyzers,
+			stylecheck.Analyzers,
+		) {
+			// Same as --checks=all,-ST1000,-ST1023
+			switch a.Analyzer.Name {
+			case "ST1000", "ST1023":
+			default:
+				out = append(out, a.Analyzer)
+			}
+		}
+
+		out = append(out, unusedAnalyzer)
+	}
+
+	return out
+}
+
+// dedupe will remove duplicate findings, such as those found in both
+// a package and its test variant, preserving order.
+func dedupe(found []Finding) []Finding {
+	var key string
+	var out []Finding
+	var seen map[string]bool = map[string]bool{}
+
+	for _, f := range found {
+		key = fmt.Sprint(f.Tool, f.File, f.Line, f.Col, f.Msg)
+
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, f)
+		}
+	}
+
+	return out
+}
+
+// guard will wrap the Run function of the provided analyzers, and
+// those they require, so that a panic (e.g. on syntax newer than an
+// analyzer supports) fails the analysis rather than the process.
+func guard(all []*analysis.Analyzer) {
+	guardedMutex.Lock()
+	defer guardedMutex.Unlock()
+
+	if guarded == nil {
+		guarded = map[*analysis.Analyzer]bool{}
+	}
+
+	guardAll(all)
+}
+
+func guardAll(all []*analysis.Analyzer) {
+	for _, a := range all {
+		if guarded[a] {
+			continue
+		}
+
+		guarded[a] = true
+		guardAll(a.Requires)
+		a.Run = guardRun(a.Name, a.Run)
+	}
+}
+
+func guardRun(
+	name string, run func(*analysis.Pass) (any, error),
+) func(*analysis.Pass) (any, error) {
+	return func(pass *analysis.Pass) (res any, e error) {
+		defer func() {
+			if r := recover(); r != nil {
+				e = fmt.Errorf("%s panicked: %v", name, r)
+			}
+		}()
+
+		return run(pass)
+	}
+}
+
+// relPath will return the provided path relative to the current
+// directory, if possible.
+func relPath(fn string) string {
+	var cwd string
+	var e error
+	var rel string
+
+	if fn == "" {
+		return ""
+	}
+
+	if cwd, e = os.Getwd(); e != nil {
+		return fn
+	}
+
+	if rel, e = filepath.Rel(cwd, fn); e != nil {
+		return fn
+	}
+
+	if strings.HasPrefix(rel, "..") {
+		return fn
+	}
+
+	return rel
+}
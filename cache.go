@@ -0,0 +1,331 @@
+package gocomplain
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// The GOROOT and GOMODCACHE directories, which are only looked up
+// once
+var cacheRoots func() []string = sync.OnceValue(cacheRootDirs)
+
+// The analyzers, as identified by cacheToolVersions, which is only
+// run once
+var cacheTool func() string = sync.OnceValue(cacheToolVersions)
+
+// CacheClean will remove all cached findings.
+func CacheClean() error {
+	var dir string
+	var e error
+
+	if dir, e = CacheDir(); e != nil {
+		return e
+	}
+
+	if e = os.RemoveAll(dir); e != nil {
+		return fmt.Errorf("failed to remove %s: %w", dir, e)
+	}
+
+	return nil
+}
+
+// CacheDir will return the directory used to store cached findings.
+func CacheDir() (string, error) {
+	var dir string
+	var e error
+
+	if dir, e = os.UserCacheDir(); e != nil {
+		return "", fmt.Errorf("user has no cache directory: %w", e)
+	}
+
+	return filepath.Join(dir, "gocomplain"), nil
+}
+
+// CacheStats will return the number of cached entries and their
+// combined size in bytes.
+func CacheStats() (uint, int64, error) {
+	var dir string
+	var e error
+	var entries uint
+	var size int64
+
+	if dir, e = CacheDir(); e != nil {
+		return 0, 0, e
+	}
+
+	e = filepath.WalkDir(
+		dir,
+		func(fn string, d fs.DirEntry, e error) error {
+			var info fs.FileInfo
+
+			if e != nil {
+				return e
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			if info, e = d.Info(); e != nil {
+				return e
+			}
+
+			entries++
+			size += info.Size()
+
+			return nil
+		},
+	)
+	if (e != nil) && !os.IsNotExist(e) {
+		return 0, 0, fmt.Errorf("failed to read %s: %w", dir, e)
+	}
+
+	return entries, size, nil
+}
+
+// cacheFiles will return the files that affect the analysis of the
+// provided packages: their compiled Go files, other files (e.g. C and
+// assembly), and embedded files, as well as those of any
+// dependencies which aren't versioned by the toolchain or go.sum.
+func cacheFiles(pkgs []*packages.Package) []string {
+	var files []string
+
+	packages.Visit(
+		pkgs,
+		nil,
+		func(pkg *packages.Package) {
+			if cacheVersioned(pkg.Dir) {
+				return
+			}
+
+			files = slices.Concat(
+				files,
+				pkg.CompiledGoFiles,
+				pkg.OtherFiles,
+				pkg.EmbedFiles,
+			)
+		},
+	)
+
+	slices.Sort(files)
+
+	return slices.Compact(files)
+}
+
+func cacheGet(key string) ([]string, bool) {
+	var b []byte
+	var dir string
+	var e error
+
+	if dir, e = CacheDir(); e != nil {
+		return nil, false
+	}
+
+	b, e = os.ReadFile(filepath.Join(dir, key[:2], key))
+	if e != nil {
+		return nil, false
+	}
+
+	if len(b) == 0 {
+		return nil, true
+	}
+
+	return strings.Split(string(b), "\n"), true
+}
+
+func cacheHashFile(h hash.Hash, fn string) {
+	var e error
+	var f *os.File
+
+	fmt.Fprintf(h, "file %s\x00", fn)
+
+	if f, e = os.Open(fn); e != nil {
+		return
+	}
+	defer f.Close()
+
+	_, _ = io.Copy(h, f)
+}
+
+// cacheKey will return a key unique to the provided command, the
+// relevant environment, the analyzers, and the contents of the
+// provided files.
+func cacheKey(cmd []string, files ...string) string {
+	var h hash.Hash = sha256.New()
+
+	for _, arg := range cmd {
+		fmt.Fprintf(h, "arg %s\x00", arg)
+	}
+
+	for _, env := range cacheEnv {
+		fmt.Fprintf(h, "env %s=%s\x00", env, os.Getenv(env))
+	}
+
+	fmt.Fprintf(h, "tool %s\x00", cacheTool())
+
+	files = append([]string{"go.mod", "go.sum"}, files...)
+
+	for _, fn := range files {
+		cacheHashFile(h, fn)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheLoad will return the packages matching the provided
+// patterns, with their test variants, grouped by directory. Each
+// directory is analyzed, and cached, as a unit.
+func cacheLoad(
+	patterns ...string,
+) (map[string][]*packages.Package, error) {
+	var cfg *packages.Config = &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles |
+			packages.NeedCompiledGoFiles | packages.NeedEmbedFiles |
+			packages.NeedImports | packages.NeedDeps,
+		Tests: true,
+	}
+	var dirs map[string][]*packages.Package
+	var e error
+	var pkgs []*packages.Package
+
+	if pkgs, e = packages.Load(cfg, patterns...); e != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", e)
+	}
+
+	dirs = map[string][]*packages.Package{}
+
+	for _, pkg := range pkgs {
+		// Generated test mains aren't analyzed
+		if strings.HasSuffix(pkg.ID, ".test") {
+			continue
+		} else if pkg.Dir == "" {
+			return nil, fmt.Errorf("failed to locate %s", pkg.ID)
+		}
+
+		dirs[pkg.Dir] = append(dirs[pkg.Dir], pkg)
+	}
+
+	return dirs, nil
+}
+
+// cacheModule will return the module path declared in go.mod, if
+// any.
+func cacheModule() string {
+	var e error
+	var f *os.File
+	var s *bufio.Scanner
+
+	if f, e = os.Open("go.mod"); e != nil {
+		return ""
+	}
+	defer f.Close()
+
+	s = bufio.NewScanner(f)
+	for s.Scan() {
+		if mod, ok := strings.CutPrefix(s.Text(), "module "); ok {
+			return strings.Trim(strings.TrimSpace(mod), "\"")
+		}
+	}
+
+	return ""
+}
+
+func cachePut(key string, out []string) {
+	var dir string
+	var e error
+
+	if dir, e = CacheDir(); e != nil {
+		return
+	}
+
+	dir = filepath.Join(dir, key[:2])
+
+	if e = os.MkdirAll(dir, 0o700); e != nil {
+		return
+	}
+
+	_ = os.WriteFile(
+		filepath.Join(dir, key),
+		[]byte(strings.Join(out, "\n")),
+		0o600,
+	)
+}
+
+// cacheRootDirs will return the GOROOT and GOMODCACHE directories.
+func cacheRootDirs() []string {
+	var env string
+
+	env, _ = execute([]string{"go", "env", "GOROOT", "GOMODCACHE"})
+
+	return strings.Split(env, "\n")
+}
+
+// cacheToolVersions will return what identifies the analyzers: the
+// Go toolchain that loads packages, the Go version gocomplain was
+// built with, and the versions of the modules providing the
+// analyzers.
+func cacheToolVersions() string {
+	var env string
+	var info *debug.BuildInfo
+	var ok bool
+	var sb strings.Builder
+
+	sb.WriteString(runtime.Version())
+
+	env, _ = execute([]string{"go", "env", "GOVERSION", "GOROOT"})
+	sb.WriteString(" " + strings.Join(strings.Fields(env), " "))
+
+	if info, ok = debug.ReadBuildInfo(); !ok {
+		return sb.String()
+	}
+
+	sb.WriteString(" " + info.Main.Version)
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.modified", "vcs.revision":
+			sb.WriteString(" " + setting.Value)
+		}
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+
+		sb.WriteString(" " + dep.Path + "@" + dep.Version)
+	}
+
+	return sb.String()
+}
+
+// cacheVersioned will return whether or not the provided directory is
+// within GOROOT or GOMODCACHE, whose files are identified by the
+// toolchain and go.sum, respectively.
+func cacheVersioned(dir string) bool {
+	for _, root := range cacheRoots() {
+		if root == "" {
+			continue
+		}
+
+		if strings.HasPrefix(dir, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+package gocomplain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestCacheKey(t *testing.T) {
+	var files map[string]string = map[string]string{
+		"a/a.go": "package a\n\n" +
+			"import (\n" +
+			"\t_ \"embed\"\n\n" +
+			"\t\"example.com/m/b\"\n" +
+			")\n\n" +
+			"//go:embed a.txt\n" +
+			"var A string\n\n" +
+			"var B = b.B\n",
+		"a/a.s":       "",
+		"a/a.txt":     "a\n",
+		"a/a_test.go": "package a\n",
+		"a/notes.md":  "a\n",
+		"b/b.go":      "package b\n\nconst B = 1\n",
+		"c/c.go":      "package c\n",
+		"go.mod":      "module example.com/m\n\ngo 1.21\n",
+	}
+	var tests []struct {
+		changed bool
+		fn      string
+	} = []struct {
+		changed bool
+		fn      string
+	}{
+		{changed: true, fn: "a/a.go"},
+		{changed: true, fn: "a/a.s"},
+		{changed: true, fn: "a/a.txt"},
+		{changed: true, fn: "a/a_test.go"},
+		{changed: true, fn: "b/b.go"},
+		{changed: true, fn: "go.mod"},
+		{changed: false, fn: "a/notes.md"},
+		{changed: false, fn: "c/c.go"},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.fn,
+			func(t *testing.T) {
+				var after string
+				var before string
+				var e error
+				var f *os.File
+
+				t.Chdir(t.TempDir())
+
+				for fn, src := range files {
+					e = os.MkdirAll(filepath.Dir(fn), 0o700)
+					if e != nil {
+						t.Fatal(e)
+					}
+
+					spellWrite(t, fn, src)
+				}
+
+				before = cacheTestKey(t, "a")
+
+				f, e = os.OpenFile(
+					test.fn,
+					os.O_APPEND|os.O_WRONLY,
+					0,
+				)
+				if e != nil {
+					t.Fatal(e)
+				}
+
+				_, e = f.WriteString("// changed\n")
+				f.Close()
+
+				if e != nil {
+					t.Fatal(e)
+				}
+
+				after = cacheTestKey(t, "a")
+
+				if (before != after) != test.changed {
+					t.Errorf(
+						"got changed %t, want %t",
+						before != after,
+						test.changed,
+					)
+				}
+			},
+		)
+	}
+}
+
+// cacheTestKey will return the cache key of the package in the
+// provided directory.
+func cacheTestKey(t *testing.T, dir string) string {
+	var cwd string
+	var dirs map[string][]*packages.Package
+	var e error
+
+	t.Helper()
+
+	if dirs, e = cacheLoad("./..."); e != nil {
+		t.Fatal(e)
+	}
+
+	if cwd, e = os.Getwd(); e != nil {
+		t.Fatal(e)
+	}
+
+	dir = filepath.Join(cwd, dir)
+
+	if len(dirs[dir]) == 0 {
+		t.Fatalf("package %s not found", dir)
+	}
+
+	return cacheKey(nil, cacheFiles(dirs[dir])...)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/mjwhitta/cli"
@@ -24,15 +25,26 @@ const (
 // Flags
 var flags struct {
 	cgo        bool
+	cognitive  uint
 	confidence float64
 	debug      bool
+	display    bool
+	exempt     cli.StringList
+	fix        bool
+	fixExport  bool
+	format     string
 	ignore     cli.StringList
 	length     uint
+	nocache    bool
 	nocolor    bool
+	output     cli.StringList
 	over       uint
 	prune      cli.StringList
 	quiet      bool
 	skip       cli.StringList
+	tabwidth   uint
+	tags       cli.StringList
+	top        uint
 	verbose    bool
 	version    bool
 }
@@ -58,53 +70,61 @@ func init() {
 	)
 	cli.Info(
 		"GoComplain combines multiple other Go source analyzing",
-		"tools. Currently supported functionality includes: gocyclo,",
-		"gofmt, gofumpt, golint, go vet, ineffassign, line-length",
-		"verification, spellcheck, and staticcheck. The spellcheck",
-		"functionality uses the misspell Go module as well as",
-		"codespell on Linux and macOS. Any provided CLI flags will",
-		"override values in ~/.config/gocomplain/rc.",
+		"tools. Currently supported functionality includes: go",
+		"build, cyclomatic and cognitive complexity, gofmt, gofumpt,",
+		"lint, go vet, ineffassign, line-length verification,",
+		"spellcheck, and staticcheck. The spellcheck functionality",
+		"uses embedded dictionaries (codespell's and misspell's,",
+		"plus its own list of ambiguous misspellings), so it works",
+		"on every OS. Any provided CLI flags will override values",
+		"in ~/.config/gocomplain/rc.",
 	)
 	cli.SectionAligned(
 		"ACTIONS - COMMANDS",
 		"|",
+		"cache clean|Remove cached findings.\n",
+		"cache stats|Show cache location and size.\n",
+		"complexity|Show average and most complex functions.\n",
 		"help, h|Display this help message.\n",
 		"install, i|Install underlying tools.\n",
+		"lsp|Serve diagnostics over stdio (Language Server).\n",
+		"tui|Browse and triage findings interactively.\n",
 		"update, upgrade, u|Reinstall underlying tools.\n",
-		"version, v|Show version.",
+		"version, v|Show version.\n",
+		"watch|Re-run tools as files change.",
 	)
 	cli.SectionAligned(
 		"ACTIONS - ENV",
 		"|",
-		"dlw|Check all supported GOOS.\n",
+		"dlw|Check darwin, linux, and windows.\n",
 		"darwin, d|Set GOOS to darwin.\n",
 		"linux, l|Set GOOS to linux.\n",
-		"windows, w|Set GOOS to windows.",
+		"windows, w|Set GOOS to windows.\n",
+		"GOOS[/GOARCH]|Set GOOS and GOARCH (see go tool dist list).",
 	)
 	cli.SectionAligned(
 		"ACTIONS - TOOLS",
 		"|",
 		"all|Run all tools (default).\n",
-		"gocyclo, cyclo|Run gocyclo.\n",
+		"build|Compile code (go build), discarding binaries.\n",
+		"gocyclo, cyclo|Check cyclomatic/cognitive complexity.\n",
 		"gofmt, fmt|Run gofmt.\n",
 		"gofumpt, fumpt|Run gofumpt.\n",
-		"golint, lint|Run golint.\n",
+		"golint, lint|Lint code (rules configured in lint).\n",
 		"govet, vet|Run govet.\n",
 		"ineffassign, ineff|Run ineffassign.\n",
 		"line-length, ll|Check source code line-length.\n",
 		"no*|Prepend tool name with \"no\" to disable that tool.\n",
 		"spellcheck, spell|Run spellchecker.\n",
-		"staticcheck, static|Run staticcheck.",
+		"staticcheck, static|Run staticcheck.\n",
+		"unchecked|Report files excluded from every target/tags.",
 	)
 	cli.SeeAlso = []string{
-		"codespell",
+		"go build",
 		"go vet",
-		"gocyclo",
 		"gofmt",
 		"gofumpt",
-		"golint",
 		"ineffassign",
-		"misspell",
 		"staticcheck",
 	}
 	cli.Title = "GoComplain"
@@ -114,14 +134,22 @@ func init() {
 		&flags.cgo,
 		"cgo",
 		false,
-		"Set environment variables for CGO support.",
+		"Set environment variables for CGO support, using the",
+		"cross compilers configured per target in cgo.",
+	)
+	cli.Flag(
+		&flags.cognitive,
+		"cognitive",
+		15,
+		"Only complain about functions over specified cognitive",
+		"complexity (default: 15).",
 	)
 	cli.Flag(
 		&flags.confidence,
 		"c",
 		"confidence",
 		0.8,
-		"Only complain about golint problems with specified minimum",
+		"Only complain about lint problems with specified minimum",
 		"confidence (default: 0.8).",
 	)
 	cli.Flag(
@@ -132,6 +160,44 @@ func init() {
 		"Enable printing of executed sub-processes.",
 		true,
 	)
+	cli.Flag(
+		&flags.display,
+		"display-width",
+		false,
+		"Measure line-length by display width, so East Asian wide",
+		"characters and emoji count as two columns.",
+	)
+	cli.Flag(
+		&flags.exempt,
+		"e",
+		"exempt",
+		"Exempt imports, strings, and/or urls (in comments) when",
+		"checking line-length.",
+	)
+	cli.Flag(
+		&flags.fix,
+		"fix",
+		false,
+		"Fix problems where possible: wrap long calls, composite",
+		"literals, and signatures when checking line-length, and",
+		"fix misspellings (prompting if ambiguous) when checking",
+		"spelling.",
+	)
+	cli.Flag(
+		&flags.fixExport,
+		"fix-exported",
+		false,
+		"Also rename misspelled exported identifiers with --fix.",
+	)
+	cli.Flag(
+		&flags.format,
+		"f",
+		"format",
+		"text",
+		"Output findings in the specified format: github, gitlab,",
+		"html, junit, markdown, quickfix, sarif, or text",
+		"(default: text).",
+	)
 	cli.Flag(
 		&flags.ignore,
 		"i",
@@ -145,19 +211,31 @@ func init() {
 		70,
 		"Set max length of source code lines (default: 70).",
 	)
+	cli.Flag(
+		&flags.nocache,
+		"no-cache",
+		false,
+		"Disable reuse of findings for unchanged packages.",
+	)
 	cli.Flag(
 		&flags.nocolor,
 		"no-color",
 		false,
 		"Disable colorized output.",
 	)
+	cli.Flag(
+		&flags.output,
+		"output",
+		"Write findings as FORMAT to PATH, given as FORMAT=PATH",
+		"(can be repeated). A bare PATH uses --format.",
+	)
 	cli.Flag(
 		&flags.over,
 		"o",
 		"over",
 		15,
-		"Only complain about functions over specified complexity",
-		"(default: 15).",
+		"Only complain about functions over specified cyclomatic",
+		"complexity (default: 15).",
 	)
 	cli.Flag(
 		&flags.prune,
@@ -177,7 +255,29 @@ func init() {
 		"s",
 		"skip",
 		"Skip directories/files (accepts globs) when checking",
-		"spelling (not used by misspell).",
+		"spelling.",
+	)
+	cli.Flag(
+		&flags.tabwidth,
+		"tab-width",
+		4,
+		"Count tabs as specified number of columns when checking",
+		"line-length (default: 4).",
+	)
+	cli.Flag(
+		&flags.tags,
+		"t",
+		"tags",
+		"Also run go build, go vet, ineffassign, and staticcheck",
+		"with the specified build tags (can be repeated, once per",
+		"tag set).",
+	)
+	cli.Flag(
+		&flags.top,
+		"top",
+		10,
+		"Show specified number of most complex functions with",
+		"complexity command (default: 10).",
 	)
 	cli.Flag(
 		&flags.verbose,
@@ -187,35 +287,58 @@ func init() {
 		"Show stacktrace, if error.",
 	)
 	cli.Flag(&flags.version, "V", "version", false, "Show version.")
-	cli.Parse()
+}
+
+// splitCommas will split each of the provided strings at commas.
+func splitCommas(list []string) []string {
+	var out []string = []string{}
+
+	for _, str := range list {
+		out = append(out, strings.Split(str, ",")...)
+	}
+
+	return out
+}
+
+// validFormat will return whether or not the provided format is
+// supported.
+func validFormat(format string) bool {
+	switch format {
+	case "github", "gitlab", "html", "junit", "markdown":
+		return true
+	case "quickfix", "sarif", "text":
+		return true
+	}
+
+	return false
 }
 
 // Process cli flags and ensure no issues
 func validate() {
-	var tmp []string
-
 	hl.Disable(flags.nocolor)
 
-	for _, arg := range cli.Args() {
-		switch arg {
-		case "h", "help":
-			cli.Usage(0)
-		case "i", "install", "u", "update", "upgrade":
-			if cli.NArg() != 1 {
-				cli.Usage(ExtraArgument)
-			}
-		case "v", "version":
-			flags.version = true
-		}
-	}
+	validateArgs()
 
 	// Validate cli flags
+	if !validFormat(flags.format) {
+		log.ErrX(
+			InvalidOption,
+			hl.Sprintf("Unknown format %s", flags.format),
+		)
+	}
+
+	validateOutputs()
+
 	if flags.length < 70 {
 		log.ErrX(InvalidOption, "Less than 70? Who hurt you?")
 	} else if flags.length > 100 {
 		log.ErrX(InvalidOption, "Greater than 100? You monster!")
 	}
 
+	if flags.tabwidth == 0 {
+		log.ErrX(InvalidOption, "Tab width must be at least 1")
+	}
+
 	// Short circuit, if version was requested
 	if flags.version {
 		hl.Printf("gocomplain version %s\n", gocomplain.Version)
@@ -223,20 +346,77 @@ func validate() {
 	}
 
 	// Fix string lists
-	for _, ignore := range flags.ignore {
-		tmp = append(tmp, strings.Split(ignore, ",")...)
+	flags.exempt = splitCommas(flags.exempt)
+	flags.ignore = splitCommas(flags.ignore)
+	flags.prune = splitCommas(flags.prune)
+	flags.skip = splitCommas(flags.skip)
+
+	for _, exempt := range flags.exempt {
+		if !slices.Contains(gocomplain.LineLengthExemptions, exempt) {
+			log.ErrX(
+				InvalidOption,
+				hl.Sprintf("Unknown exemption %s", exempt),
+			)
+		}
 	}
-	flags.ignore = tmp
+}
+
+// validateArgs will ensure commands have the right number of
+// arguments, and handle help and version.
+func validateArgs() {
+	for _, arg := range cli.Args() {
+		switch arg {
+		case "cache":
+			if cli.NArg() < 2 {
+				cli.Usage(MissingArgument)
+			} else if cli.NArg() > 2 {
+				cli.Usage(ExtraArgument)
+			}
+
+			switch cli.Arg(1) {
+			case "clean", "stats":
+			default:
+				cli.Usage(InvalidArgument)
+			}
+		case "h", "help":
+			cli.Usage(0)
+		case "complexity", "i", "install", "u", "update", "upgrade":
+			if cli.NArg() != 1 {
+				cli.Usage(ExtraArgument)
+			}
+		case "v", "version":
+			flags.version = true
+		}
+	}
+}
+
+// validateOutputs will parse the --output flags, and decide whether
+// or not the report also goes to stdout.
+func validateOutputs() {
+	var o reportOutput
+	var stdout bool = true
+
+	for _, str := range flags.output {
+		o = parseOutput(str)
+
+		if !validFormat(o.format) {
+			log.ErrX(
+				InvalidOption,
+				hl.Sprintf("Unknown format %s", o.format),
+			)
+		}
+
+		if o.format == flags.format {
+			stdout = false
+		}
 
-	tmp = []string{}
-	for _, prune := range flags.prune {
-		tmp = append(tmp, strings.Split(prune, ",")...)
+		outputs = append(outputs, o)
 	}
-	flags.prune = tmp
 
-	tmp = []string{}
-	for _, skip := range flags.skip {
-		tmp = append(tmp, strings.Split(skip, ",")...)
+	// Formats that aren't printed live go to stdout by default, so
+	// progress messages would corrupt them
+	if stdout && !live(flags.format) {
+		outputs = append(outputs, reportOutput{format: flags.format})
+		flags.quiet = true
 	}
-	flags.skip = tmp
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/mjwhitta/gocomplain"
+	"github.com/mjwhitta/log"
+)
+
+// Running totals used to average the complexity of a package
+type complexityTotal struct {
+	cognitive  int
+	cyclomatic int
+	funcs      int
+}
+
+// complexity will show the average complexity of each package and
+// the most complex functions. Files which fail to parse are skipped,
+// and their errors are returned once the rest are shown.
+func complexity() error {
+	var dirs []string
+	var e error
+	var funcs []gocomplain.Complexity
+	var src map[string][]string
+	var t *complexityTotal
+	var tests map[string][]string
+	var totals map[string]*complexityTotal
+
+	totals = map[string]*complexityTotal{}
+
+	src, tests, _ = gocomplain.FindSrcFiles(".", flags.prune...)
+
+	funcs, e = gocomplain.Complexities(src, tests)
+
+	for _, c := range funcs {
+		if totals[c.Dir] == nil {
+			dirs = append(dirs, c.Dir)
+			totals[c.Dir] = &complexityTotal{}
+		}
+
+		totals[c.Dir].cognitive += c.Cognitive
+		totals[c.Dir].cyclomatic += c.Cyclomatic
+		totals[c.Dir].funcs++
+	}
+
+	slices.Sort(dirs)
+
+	log.Info("Average complexity (cyclomatic, cognitive):")
+	for _, dir := range dirs {
+		t = totals[dir]
+		log.SubInfof(
+			"%s: %.2f, %.2f",
+			dir,
+			float64(t.cyclomatic)/float64(t.funcs),
+			float64(t.cognitive)/float64(t.funcs),
+		)
+	}
+
+	slices.SortStableFunc(
+		funcs,
+		func(a gocomplain.Complexity, b gocomplain.Complexity) int {
+			return cmp.Or(
+				cmp.Compare(b.Cognitive, a.Cognitive),
+				cmp.Compare(b.Cyclomatic, a.Cyclomatic),
+				strings.Compare(a.Func, b.Func),
+			)
+		},
+	)
+
+	funcs = funcs[:min(len(funcs), int(flags.top))]
+
+	log.Infof(
+		"Top %d most complex functions (cyclomatic, cognitive):",
+		len(funcs),
+	)
+	for _, c := range funcs {
+		log.SubInfof(
+			"%s: %s (%d, %d)",
+			c.Pos,
+			c.Func,
+			c.Cyclomatic,
+			c.Cognitive,
+		)
+	}
+
+	return e
+}
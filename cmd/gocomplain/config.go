@@ -2,26 +2,91 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/mjwhitta/gocomplain"
 )
 
 type config struct {
+	CGO        cgoMap   `json:"cgo"`
+	Cognitive  uint     `json:"cognitive"`
 	Confidence float64  `json:"confidence"`
+	Display    bool     `json:"displaywidth"`
+	Exempt     []string `json:"exempt"`
 	file       string   `json:"-"`
 	Ignore     []string `json:"ignore"`
 	Length     uint     `json:"length"`
+	Lint       lintMap  `json:"lint"`
 	Over       uint     `json:"over"`
 	Prune      []string `json:"prune"`
 	Quiet      bool     `json:"quiet"`
 	Skip       []string `json:"skip"`
+	TabWidth   uint     `json:"tabwidth"`
+	Tags       []string `json:"tags"`
+	Targets    []string `json:"targets"`
+	Top        uint     `json:"top"`
 }
 
+// Maps GOOS or GOOS/GOARCH targets to CGO environment variables
+type cgoMap map[string]map[string]string
+
+// Maps lint rule names to their configuration, like revive
+type lintMap map[string]gocomplain.LintRule
+
 var cfg *config
 
+// Cross compilers used with --cgo, if not configured
+var defaultCGO cgoMap = cgoMap{
+	"windows": {"CC": "x86_64-w64-mingw32-gcc"},
+}
+
+// fillDefaults will set any config values that are missing.
+func fillDefaults() {
+	cfg.Cognitive = cmp.Or(cfg.Cognitive, 15)
+	cfg.Confidence = cmp.Or(cfg.Confidence, 0.8)
+	cfg.Length = cmp.Or(cfg.Length, 70)
+	cfg.Over = cmp.Or(cfg.Over, 15)
+	cfg.TabWidth = cmp.Or(cfg.TabWidth, 4)
+	cfg.Top = cmp.Or(cfg.Top, 10)
+
+	if cfg.CGO == nil {
+		cfg.CGO = defaultCGO
+	}
+
+	if cfg.Exempt == nil {
+		cfg.Exempt = []string{}
+	}
+
+	if cfg.Ignore == nil {
+		cfg.Ignore = []string{}
+	}
+
+	if cfg.Lint == nil {
+		cfg.Lint = lintMap{}
+	}
+
+	if cfg.Prune == nil {
+		cfg.Prune = []string{}
+	}
+
+	if cfg.Skip == nil {
+		cfg.Skip = []string{}
+	}
+
+	if cfg.Tags == nil {
+		cfg.Tags = []string{}
+	}
+
+	if cfg.Targets == nil {
+		cfg.Targets = []string{}
+	}
+}
+
 func init() {
 	var b []byte
 	var e error
@@ -36,15 +101,8 @@ func init() {
 
 	if (e != nil) || (len(bytes.TrimSpace(b)) == 0) {
 		// Default cfg
-		cfg = &config{
-			Confidence: 0.8,
-			file:       fn,
-			Ignore:     []string{},
-			Length:     70,
-			Over:       15,
-			Prune:      []string{},
-			Skip:       []string{},
-		}
+		cfg = &config{file: fn}
+		fillDefaults()
 
 		if e = cfg.Save(); e != nil {
 			panic(e)
@@ -53,30 +111,8 @@ func init() {
 		if e = json.Unmarshal(b, &cfg); e != nil {
 			panic(fmt.Errorf("invalid cfg: %w", e))
 		}
-	}
-
-	if cfg.Confidence == 0 {
-		cfg.Confidence = 0.8
-	}
 
-	if cfg.Ignore == nil {
-		cfg.Ignore = []string{}
-	}
-
-	if cfg.Length == 0 {
-		cfg.Length = 70
-	}
-
-	if cfg.Over == 0 {
-		cfg.Over = 15
-	}
-
-	if cfg.Prune == nil {
-		cfg.Prune = []string{}
-	}
-
-	if cfg.Skip == nil {
-		cfg.Skip = []string{}
+		fillDefaults()
 	}
 }
 
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjwhitta/gocomplain"
+	hl "github.com/mjwhitta/hilighter"
+)
+
+// ghEscape will escape the provided string for use in a GitHub
+// Actions workflow command. Property values also escape separators.
+func ghEscape(str string, property bool) string {
+	str = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	).Replace(str)
+
+	if property {
+		str = strings.NewReplacer(
+			":", "%3A",
+			",", "%2C",
+		).Replace(str)
+	}
+
+	return str
+}
+
+// ghLevel will return the GitHub annotation level for the provided
+// finding.
+func ghLevel(f gocomplain.Finding) string {
+	switch severity(f) {
+	case "major":
+		return "error"
+	case "minor":
+		return "warning"
+	}
+
+	return "notice"
+}
+
+func writeGitHub(w io.Writer, found []gocomplain.Finding) error {
+	var e error
+	var props []string
+	var sb strings.Builder
+
+	for _, f := range sortFindings(found) {
+		props = nil
+
+		if f.File != "" {
+			props = append(
+				props,
+				"file="+ghEscape(filepath.ToSlash(f.File), true),
+			)
+
+			if f.Line > 0 {
+				props = append(props, hl.Sprintf("line=%d", f.Line))
+			}
+
+			if f.Col > 0 {
+				props = append(props, hl.Sprintf("col=%d", f.Col))
+			}
+		}
+
+		props = append(props, "title="+ghEscape(f.Tool, true))
+
+		sb.WriteString(
+			hl.Sprintf(
+				"::%s %s::%s\n",
+				ghLevel(f),
+				strings.Join(props, ","),
+				ghEscape(f.Msg+labels(f), false),
+			),
+		)
+	}
+
+	if _, e = io.WriteString(w, sb.String()); e != nil {
+		return fmt.Errorf("failed to write GitHub annotations: %w", e)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjwhitta/gocomplain"
+)
+
+type glIssue struct {
+	CheckName   string     `json:"check_name"`
+	Description string     `json:"description"`
+	Fingerprint string     `json:"fingerprint"`
+	Location    glLocation `json:"location"`
+	Severity    string     `json:"severity"`
+}
+
+type glLines struct {
+	Begin int `json:"begin"`
+}
+
+type glLocation struct {
+	Lines glLines `json:"lines"`
+	Path  string  `json:"path"`
+}
+
+// glFingerprint will return a fingerprint for the provided finding
+// that does not change when unrelated lines are added or removed.
+// The occurrence distinguishes identical findings in the same file.
+func glFingerprint(f gocomplain.Finding, occurrence int) string {
+	var sum [sha256.Size]byte = sha256.Sum256(
+		[]byte(
+			strings.Join(
+				[]string{
+					f.Tool,
+					filepath.ToSlash(f.File),
+					f.Msg,
+					fmt.Sprint(occurrence),
+				},
+				"\x00",
+			),
+		),
+	)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func writeGitLab(w io.Writer, found []gocomplain.Finding) error {
+	var e error
+	var enc *json.Encoder = json.NewEncoder(w)
+	var issue glIssue
+	var issues []glIssue = []glIssue{}
+	var key string
+	var seen map[string]int = map[string]int{}
+
+	for _, f := range sortFindings(found) {
+		key = f.Tool + "\x00" + f.File + "\x00" + f.Msg
+		seen[key]++
+
+		issue = glIssue{
+			CheckName:   f.Tool,
+			Description: f.Msg + labels(f),
+			Fingerprint: glFingerprint(f, seen[key]),
+			Location: glLocation{
+				Lines: glLines{Begin: max(f.Line, 1)},
+				Path:  filepath.ToSlash(f.File),
+			},
+			Severity: severity(f),
+		}
+
+		if issue.Location.Path == "" {
+			issue.Location.Path = "."
+		}
+
+		issues = append(issues, issue)
+	}
+
+	enc.SetIndent("", "  ")
+
+	if e = enc.Encode(issues); e != nil {
+		return fmt.Errorf(
+			"failed to write Code Quality report: %w",
+			e,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mjwhitta/gocomplain"
+)
+
+func TestGLFingerprint(t *testing.T) {
+	var a gocomplain.Finding = gocomplain.Finding{
+		File: "a.go",
+		Line: 3,
+		Msg:  "bad",
+		Tool: "govet",
+	}
+	var b gocomplain.Finding = a
+
+	// Moving a finding to another line keeps its fingerprint
+	b.Line = 10
+
+	if glFingerprint(a, 1) != glFingerprint(b, 1) {
+		t.Error("fingerprint changed with the line")
+	}
+
+	if glFingerprint(a, 1) == glFingerprint(a, 2) {
+		t.Error("fingerprint ignored the occurrence")
+	}
+
+	b.Msg = "worse"
+
+	if glFingerprint(a, 1) == glFingerprint(b, 1) {
+		t.Error("fingerprint ignored the message")
+	}
+}
+
+func TestWriteGitLab(t *testing.T) {
+	var buf bytes.Buffer
+	var e error
+	var found []gocomplain.Finding = []gocomplain.Finding{
+		{File: "a.go", Line: 3, Msg: "bad", Tool: "govet"},
+		{File: "a.go", Line: 7, Msg: "bad", Tool: "govet"},
+		{
+			File: "b.go",
+			GOOS: []string{"linux"},
+			Line: 1,
+			Msg:  "teh ==> the",
+			Tool: "spellcheck",
+		},
+		{Msg: "no position", Tool: "build"},
+	}
+	var issues []glIssue
+
+	if e = writeGitLab(&buf, nil); e != nil {
+		t.Fatal(e)
+	}
+
+	// No findings is an empty report, not null
+	if buf.String() != "[]\n" {
+		t.Errorf("got %q, want []", buf.String())
+	}
+
+	buf.Reset()
+
+	if e = writeGitLab(&buf, found); e != nil {
+		t.Fatal(e)
+	}
+
+	if e = json.Unmarshal(buf.Bytes(), &issues); e != nil {
+		t.Fatalf("invalid JSON: %s", e)
+	}
+
+	if len(issues) != len(found) {
+		t.Fatalf("got %d issues, want %d", len(issues), len(found))
+	}
+
+	// Issues are sorted, and those without a file are for the
+	// project
+	if issues[0].Location.Path != "." {
+		t.Errorf("got path %q, want .", issues[0].Location.Path)
+	}
+
+	if line := issues[0].Location.Lines.Begin; line != 1 {
+		t.Errorf("got line %d, want 1", line)
+	}
+
+	if issues[0].Severity != "major" {
+		t.Errorf("got severity %s, want major", issues[0].Severity)
+	}
+
+	if issues[1].Fingerprint == issues[2].Fingerprint {
+		t.Error("identical findings have the same fingerprint")
+	}
+
+	if issues[3].Description != "teh ==> the (linux)" {
+		t.Errorf("got description %q", issues[3].Description)
+	}
+
+	if issues[3].Severity != "info" {
+		t.Errorf("got severity %s, want info", issues[3].Severity)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"html"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mjwhitta/gocomplain"
+	hl "github.com/mjwhitta/hilighter"
+)
+
+// Lines of source shown around each finding in reports
+const snippetContext int = 2
+
+type htmlCount struct {
+	Count int
+	Name  string
+}
+
+type htmlFinding struct {
+	gocomplain.Finding
+	OS      string
+	Package string
+	Snippet template.HTML
+}
+
+type htmlReport struct {
+	Findings []htmlFinding
+	Packages []htmlCount
+	Tools    []htmlCount
+	Version  string
+}
+
+//go:embed report.html.tmpl
+var htmlSrc string
+
+var htmlTmpl *template.Template = template.Must(
+	template.New("report").Parse(htmlSrc),
+)
+
+// highlight will return the provided line of Go source as HTML, with
+// keywords, literals, and comments wrapped in styled spans.
+func highlight(line string) string {
+	var class string
+	var f *token.File
+	var lit string
+	var offset int
+	var out strings.Builder
+	var pos token.Pos
+	var s scanner.Scanner
+	var tok token.Token
+
+	f = token.NewFileSet().AddFile("", -1, len(line))
+	s.Init(f, []byte(line), func(token.Position, string) {}, 1)
+
+	for {
+		if pos, tok, lit = s.Scan(); tok == token.EOF {
+			break
+		}
+
+		// Skip automatically inserted semicolons
+		if (tok == token.SEMICOLON) && (lit == "\n") {
+			continue
+		}
+
+		switch {
+		case tok == token.COMMENT:
+			class = "com"
+		case tok.IsKeyword():
+			class = "kw"
+		case (tok == token.CHAR) || (tok == token.STRING):
+			class = "str"
+		case tok.IsLiteral() && (tok != token.IDENT):
+			class = "num"
+		default:
+			continue
+		}
+
+		if lit == "" {
+			lit = tok.String()
+		}
+
+		out.WriteString(
+			html.EscapeString(line[offset:f.Offset(pos)]),
+		)
+		out.WriteString(
+			hl.Sprintf(
+				"<span class=\"%s\">%s</span>",
+				class,
+				html.EscapeString(lit),
+			),
+		)
+		offset = f.Offset(pos) + len(lit)
+	}
+
+	out.WriteString(html.EscapeString(line[min(offset, len(line)):]))
+
+	return out.String()
+}
+
+// htmlOf will return the provided finding with the details needed by
+// the HTML report.
+func htmlOf(f gocomplain.Finding) htmlFinding {
+	var hf htmlFinding = htmlFinding{
+		Finding: f,
+		OS:      "any",
+		Package: "(none)",
+	}
+	var lines []string
+	var sb strings.Builder
+	var start int
+
+	if len(f.GOOS) > 0 {
+		hf.OS = strings.Join(f.GOOS, ", ")
+	}
+
+	if f.File != "" {
+		hf.Package = filepath.ToSlash(filepath.Dir(f.File))
+	}
+
+	start, lines = snippet(f)
+	for i, line := range lines {
+		if strings.HasSuffix(f.File, ".go") {
+			line = highlight(line)
+		} else {
+			line = html.EscapeString(line)
+		}
+
+		if start+i == f.Line {
+			line = "<span class=\"hit\">" + line + "</span>"
+		}
+
+		sb.WriteString(
+			hl.Sprintf(
+				"<span class=\"ln\">%4d</span> %s\n",
+				start+i,
+				line,
+			),
+		)
+	}
+
+	hf.Snippet = template.HTML(sb.String())
+
+	return hf
+}
+
+// snippet will return the lines surrounding the provided finding,
+// along with the line number of the first one.
+func snippet(f gocomplain.Finding) (int, []string) {
+	var b []byte
+	var lines []string
+	var start int
+
+	if (f.File == "") || (f.Line < 1) {
+		return 0, nil
+	}
+
+	if b, _ = os.ReadFile(f.File); len(b) == 0 {
+		return 0, nil
+	}
+
+	b = bytes.ReplaceAll(b, []byte("\r"), nil)
+
+	if lines = strings.Split(string(b), "\n"); f.Line > len(lines) {
+		return 0, nil
+	}
+
+	start = max(f.Line-1-snippetContext, 0)
+	lines = lines[start:min(f.Line+snippetContext, len(lines))]
+
+	return start + 1, lines
+}
+
+// tally will count the findings by the provided key.
+func tally(
+	found []htmlFinding, key func(htmlFinding) string,
+) []htmlCount {
+	var counts []htmlCount
+	var idx map[string]int = map[string]int{}
+
+	for _, f := range found {
+		if i, ok := idx[key(f)]; ok {
+			counts[i].Count++
+			continue
+		}
+
+		idx[key(f)] = len(counts)
+		counts = append(counts, htmlCount{Count: 1, Name: key(f)})
+	}
+
+	return counts
+}
+
+func writeHTML(w io.Writer, found []gocomplain.Finding) error {
+	var e error
+	var r htmlReport = htmlReport{Version: gocomplain.Version}
+
+	for _, f := range sortFindings(found) {
+		r.Findings = append(r.Findings, htmlOf(f))
+	}
+
+	r.Packages = tally(
+		r.Findings,
+		func(f htmlFinding) string { return f.Package },
+	)
+	r.Tools = tally(
+		r.Findings,
+		func(f htmlFinding) string { return f.Tool },
+	)
+
+	if e = htmlTmpl.Execute(w, r); e != nil {
+		return fmt.Errorf("failed to write HTML report: %w", e)
+	}
+
+	return nil
+}
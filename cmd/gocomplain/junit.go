@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"github.com/mjwhitta/gocomplain"
+	hl "github.com/mjwhitta/hilighter"
+)
+
+type junitCase struct {
+	Failure *junitFailure `xml:"failure,omitempty"`
+	Name    string        `xml:"name,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+	Type    string `xml:"type,attr"`
+}
+
+type junitSuite struct {
+	Cases    []junitCase `xml:"testcase"`
+	Failures int         `xml:"failures,attr"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+}
+
+type junitSuites struct {
+	Failures int          `xml:"failures,attr"`
+	Name     string       `xml:"name,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+	Tests    int          `xml:"tests,attr"`
+	XMLName  xml.Name     `xml:"testsuites"`
+}
+
+// junitOf will return the provided finding as a failed test case.
+func junitOf(f gocomplain.Finding) junitCase {
+	var name string = filepath.ToSlash(f.File)
+
+	if name == "" {
+		name = f.Tool
+	} else if f.Line > 0 {
+		name = hl.Sprintf("%s:%d", name, f.Line)
+	}
+
+	return junitCase{
+		Failure: &junitFailure{
+			Message: f.Msg + labels(f),
+			Text:    quickfix(f),
+			Type:    f.Tool,
+		},
+		Name: name,
+	}
+}
+
+// writeJUnit will write one test suite per tool, so that tools
+// without findings are reported as passing.
+func writeJUnit(w io.Writer, found []gocomplain.Finding) error {
+	var e error
+	var enc *xml.Encoder = xml.NewEncoder(w)
+	var idx int
+	var names []string = slices.Clone(tools)
+	var r junitSuites = junitSuites{Name: "gocomplain"}
+
+	found = sortFindings(found)
+
+	for _, f := range found {
+		if !slices.Contains(names, f.Tool) {
+			names = append(names, f.Tool)
+		}
+	}
+
+	for _, name := range names {
+		r.Suites = append(r.Suites, junitSuite{Name: name})
+	}
+
+	for _, f := range found {
+		idx = slices.Index(names, f.Tool)
+		r.Suites[idx].Cases = append(r.Suites[idx].Cases, junitOf(f))
+		r.Suites[idx].Failures++
+	}
+
+	for i := range r.Suites {
+		if len(r.Suites[i].Cases) == 0 {
+			r.Suites[i].Cases = []junitCase{{Name: r.Suites[i].Name}}
+		}
+
+		r.Suites[i].Tests = len(r.Suites[i].Cases)
+		r.Failures += r.Suites[i].Failures
+		r.Tests += r.Suites[i].Tests
+	}
+
+	enc.Indent("", "  ")
+
+	if _, e = io.WriteString(w, xml.Header); e == nil {
+		e = enc.Encode(r)
+	}
+
+	if e != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", e)
+	}
+
+	return nil
+}
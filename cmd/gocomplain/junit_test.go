@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/mjwhitta/gocomplain"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	var e error
+	var found []gocomplain.Finding = []gocomplain.Finding{
+		{Col: 2, File: "a.go", Line: 3, Msg: "bad", Tool: "govet"},
+		{
+			File: "b.go",
+			Line: 1,
+			Msg:  "teh ==> the",
+			Tool: "spellcheck",
+		},
+		{Col: 1, File: "a.go", Line: 1, Msg: "worse", Tool: "govet"},
+	}
+	var r junitSuites
+	var tests []struct {
+		cases    int
+		failures int
+		name     string
+	} = []struct {
+		cases    int
+		failures int
+		name     string
+	}{
+		{cases: 1, failures: 0, name: "gofmt"},
+		{cases: 2, failures: 2, name: "govet"},
+		{cases: 1, failures: 1, name: "spellcheck"},
+	}
+
+	tools = []string{"gofmt", "govet"}
+	defer func() { tools = nil }()
+
+	if e = writeJUnit(&buf, found); e != nil {
+		t.Fatal(e)
+	}
+
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Error("missing XML header")
+	}
+
+	if e = xml.Unmarshal(buf.Bytes(), &r); e != nil {
+		t.Fatalf("invalid XML: %s", e)
+	}
+
+	if (r.Tests != 4) || (r.Failures != 3) {
+		t.Errorf("got %d tests, %d failures", r.Tests, r.Failures)
+	}
+
+	if len(r.Suites) != len(tests) {
+		t.Fatalf("got %d suites, want %d", len(r.Suites), len(tests))
+	}
+
+	for i, test := range tests {
+		if r.Suites[i].Name != test.name {
+			t.Errorf(
+				"got suite %s, want %s",
+				r.Suites[i].Name,
+				test.name,
+			)
+		}
+
+		if len(r.Suites[i].Cases) != test.cases {
+			t.Errorf(
+				"got %d %s cases, want %d",
+				len(r.Suites[i].Cases),
+				test.name,
+				test.cases,
+			)
+		}
+
+		if r.Suites[i].Failures != test.failures {
+			t.Errorf(
+				"got %d %s failures, want %d",
+				r.Suites[i].Failures,
+				test.name,
+				test.failures,
+			)
+		}
+	}
+
+	// Passing tools have a single case without a failure
+	if r.Suites[0].Cases[0].Failure != nil {
+		t.Error("gofmt should pass")
+	}
+
+	if name := r.Suites[1].Cases[0].Name; name != "a.go:1" {
+		t.Errorf("got case %s, want a.go:1", name)
+	}
+}
@@ -0,0 +1,691 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/mjwhitta/gocomplain"
+	hl "github.com/mjwhitta/hilighter"
+)
+
+// LSP diagnostic severities
+const (
+	lspError = iota + 1
+	lspWarning
+	lspInformation
+	lspHint
+)
+
+type lspAction struct {
+	Diagnostics []lspDiagnostic `json:"diagnostics,omitempty"`
+	Edit        lspEdit         `json:"edit"`
+	Kind        string          `json:"kind"`
+	Title       string          `json:"title"`
+}
+
+type lspDiagnostic struct {
+	Code     string   `json:"code,omitempty"`
+	Data     []string `json:"data,omitempty"`
+	Message  string   `json:"message"`
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity,omitempty"`
+	Source   string   `json:"source,omitempty"`
+}
+
+type lspEdit struct {
+	Changes map[string][]lspTextEdit `json:"changes"`
+}
+
+type lspMsg struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type lspPosition struct {
+	Character int `json:"character"`
+	Line      int `json:"line"`
+}
+
+type lspRange struct {
+	End   lspPosition `json:"end"`
+	Start lspPosition `json:"start"`
+}
+
+type lspServer struct {
+	docs map[string]string
+	out  io.Writer
+	pkgs map[string][]string
+}
+
+type lspTextDoc struct {
+	TextDocument struct {
+		Text string `json:"text"`
+		URI  string `json:"uri"`
+	} `json:"textDocument"`
+}
+
+type lspTextEdit struct {
+	NewText string   `json:"newText"`
+	Range   lspRange `json:"range"`
+}
+
+// lsp will serve gocomplain diagnostics over stdio using the Language
+// Server Protocol.
+func lsp() error {
+	var b []byte
+	var e error
+	var msg lspMsg
+	var r *bufio.Reader = bufio.NewReader(os.Stdin)
+	var srv *lspServer = &lspServer{
+		docs: map[string]string{},
+		out:  os.Stdout,
+		pkgs: map[string][]string{},
+	}
+
+	// Anything logged to stdout would corrupt the protocol
+	flags.quiet = true
+	gocomplain.Debug = false
+	gocomplain.Quiet = true
+
+	for {
+		if b, e = lspRead(r); e == io.EOF {
+			return nil
+		} else if e != nil {
+			return e
+		}
+
+		// Malformed messages get a parse error, like JSON-RPC says
+		msg = lspMsg{}
+		if e = json.Unmarshal(b, &msg); e != nil {
+			e = srv.fail(
+				nil,
+				-32700,
+				"invalid LSP message: "+e.Error(),
+			)
+			if e != nil {
+				return e
+			}
+
+			continue
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		if e = srv.handle(msg); e != nil {
+			return e
+		}
+	}
+}
+
+// lspPath will return the path, relative to the current directory, of
+// the provided URI.
+func lspPath(uri string) string {
+	var cwd string
+	var fn string
+	var u *url.URL
+
+	if u, _ = url.Parse(uri); (u == nil) || (u.Scheme != "file") {
+		return ""
+	}
+
+	fn = filepath.FromSlash(u.Path)
+	if (runtime.GOOS == "windows") && strings.HasPrefix(u.Path, "/") {
+		fn = filepath.FromSlash(u.Path[1:])
+	}
+
+	cwd, _ = os.Getwd()
+	if rel, e := filepath.Rel(cwd, fn); e == nil {
+		fn = rel
+	}
+
+	return fn
+}
+
+// lspRangeOf will return the range to highlight for the provided
+// finding within the provided line of text.
+func lspRangeOf(f gocomplain.Finding, text string) lspRange {
+	var end int = len(text)
+	var start int
+	var word string
+
+	if f.Col > 0 {
+		start = min(f.Col-1, len(text))
+	} else {
+		start = len(text) - len(strings.TrimLeft(text, " \t"))
+	}
+
+	switch f.Tool {
+	case "spellcheck":
+		if word, _ = f.Correction(); word != "" {
+			if i := strings.Index(text[start:], word); i >= 0 {
+				start += i
+				end = start + len(word)
+			}
+		}
+	}
+
+	return lspRange{
+		End: lspPosition{
+			Character: lspUTF16(text[:end]),
+			Line:      f.Line - 1,
+		},
+		Start: lspPosition{
+			Character: lspUTF16(text[:start]),
+			Line:      f.Line - 1,
+		},
+	}
+}
+
+func lspRead(r *bufio.Reader) ([]byte, error) {
+	var b []byte
+	var e error
+	var line string
+	var n int = -1
+
+	for {
+		if line, e = r.ReadString('\n'); e != nil {
+			return nil, e
+		}
+
+		if line = strings.TrimSpace(line); line == "" {
+			break
+		}
+
+		if v, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+			if n, e = strconv.Atoi(strings.TrimSpace(v)); e != nil {
+				return nil, fmt.Errorf("invalid LSP header: %w", e)
+			}
+		}
+	}
+
+	if n < 0 {
+		return nil, fmt.Errorf("missing LSP Content-Length header")
+	}
+
+	b = make([]byte, n)
+	if _, e = io.ReadFull(r, b); e != nil {
+		return nil, fmt.Errorf("failed to read LSP message: %w", e)
+	}
+
+	return b, nil
+}
+
+// lspRun will run the enabled tools against the provided file and
+// package, using the current environment.
+func lspRun(
+	single map[string][]string, pkg []map[string][]string,
+) []gocomplain.Finding {
+	var analyzers []string
+	var findings []gocomplain.Finding
+	var out map[string][]string = map[string][]string{}
+
+	for _, tool := range tools {
+		switch tool {
+		case "gocyclo":
+			out[tool] = gocomplain.GoCyclo(
+				flags.over,
+				flags.cognitive,
+				single,
+			)
+		case "golint":
+			if len(pkg) > 0 {
+				out[tool] = gocomplain.Lint(
+					flags.confidence,
+					cfg.Lint,
+					pkg...,
+				)
+			}
+		case "govet", "ineffassign", "staticcheck":
+			analyzers = append(analyzers, tool)
+		case "line-length":
+			out[tool] = gocomplain.LineLength(
+				flags.length,
+				flags.exempt,
+				single,
+			)
+		case "spellcheck":
+			out[tool] = gocomplain.SpellCheck(
+				flags.ignore,
+				flags.skip,
+				single,
+			)
+		}
+	}
+
+	// Packages are loaded once for all analyzers
+	if (len(analyzers) > 0) && (len(pkg) > 0) {
+		findings = gocomplain.Analyze(analyzers, pkg...)
+	}
+
+	for tool, lines := range out {
+		for _, ln := range lines {
+			findings = append(
+				findings,
+				gocomplain.ParseFinding(tool, ln),
+			)
+		}
+	}
+
+	return findings
+}
+
+func lspSend(w io.Writer, msg map[string]any) error {
+	var b []byte
+	var e error
+
+	msg["jsonrpc"] = "2.0"
+
+	if b, e = json.Marshal(msg); e != nil {
+		return fmt.Errorf("failed to encode LSP message: %w", e)
+	}
+
+	_, e = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	if e != nil {
+		return fmt.Errorf("failed to write LSP message: %w", e)
+	}
+
+	return nil
+}
+
+func lspURI(fn string) string {
+	var u url.URL = url.URL{Scheme: "file"}
+
+	if abs, e := filepath.Abs(fn); e == nil {
+		fn = abs
+	}
+
+	u.Path = filepath.ToSlash(fn)
+	if !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path // Windows drive letters
+	}
+
+	return u.String()
+}
+
+func lspUTF16(s string) int {
+	var n int
+
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+
+	return n
+}
+
+func (s *lspServer) actions(p json.RawMessage) ([]lspAction, error) {
+	var actions []lspAction = []lspAction{}
+	var e error
+	var edits []lspTextEdit
+	var params struct {
+		Context struct {
+			Diagnostics []lspDiagnostic `json:"diagnostics"`
+		} `json:"context"`
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+	}
+	var uri string
+
+	if e = json.Unmarshal(p, &params); e != nil {
+		return nil, e
+	}
+
+	uri = params.TextDocument.URI
+
+	for _, d := range params.Context.Diagnostics {
+		for _, fix := range d.Data {
+			actions = append(
+				actions,
+				lspAction{
+					Diagnostics: []lspDiagnostic{d},
+					Edit: lspEdit{
+						Changes: map[string][]lspTextEdit{
+							uri: {{NewText: fix, Range: d.Range}},
+						},
+					},
+					Kind:  "quickfix",
+					Title: hl.Sprintf("Replace with %q", fix),
+				},
+			)
+		}
+	}
+
+	if edits = s.format(uri); len(edits) > 0 {
+		actions = append(
+			actions,
+			lspAction{
+				Edit: lspEdit{
+					Changes: map[string][]lspTextEdit{uri: edits},
+				},
+				Kind:  "source.fixAll",
+				Title: "Format file (gofmt -s)",
+			},
+		)
+	}
+
+	return actions, nil
+}
+
+// change will track unsaved edits, so formatting applies to what the
+// editor is showing.
+func (s *lspServer) change(p json.RawMessage) error {
+	var e error
+	var n int
+	var params struct {
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+	}
+
+	if e = json.Unmarshal(p, &params); e != nil {
+		return e
+	}
+
+	if n = len(params.ContentChanges); n > 0 {
+		s.docs[lspPath(params.TextDocument.URI)] = params.
+			ContentChanges[n-1].Text
+	}
+
+	return nil
+}
+
+// check will run the enabled tools against the provided file and its
+// package, then publish the resulting diagnostics.
+func (s *lspServer) check(uri string) error {
+	var byFile map[string][]lspDiagnostic
+	var dir string
+	var fn string = lspPath(uri)
+	var findings []gocomplain.Finding
+	var pkg []map[string][]string
+	var seen map[string]bool = map[string]bool{}
+	var single map[string][]string
+	var src map[string][]string
+	var tests map[string][]string
+	var uris []string
+
+	if !strings.HasSuffix(fn, ".go") {
+		return nil
+	}
+
+	byFile = map[string][]lspDiagnostic{}
+	dir = filepath.Dir(fn)
+	single = map[string][]string{dir: {filepath.Base(fn)}}
+
+	src, tests, _ = gocomplain.FindSrcFiles(dir, flags.prune...)
+	for _, m := range []map[string][]string{src, tests} {
+		if len(m[dir]) > 0 {
+			pkg = append(pkg, map[string][]string{dir: m[dir]})
+		}
+	}
+
+	for _, goos := range oses {
+		setGOOS(goos)
+		findings = append(findings, lspRun(single, pkg)...)
+	}
+
+	resetGOOS()
+
+	if slices.Contains(tools, "gofmt") && (len(s.format(uri)) > 0) {
+		findings = append(
+			findings,
+			gocomplain.Finding{
+				File: fn,
+				Line: 1,
+				Msg:  "file is not formatted (gofmt -s)",
+				Tool: "gofmt",
+			},
+		)
+	}
+
+	// Findings are often identical across GOOS
+	for _, f := range findings {
+		if (f.File == "") || seen[quickfix(f)] {
+			continue
+		} else if f.Ignored(baseline) {
+			continue
+		}
+
+		seen[quickfix(f)] = true
+		byFile[f.File] = append(byFile[f.File], s.diagnostic(f))
+	}
+
+	// Clear stale diagnostics from the last check of this package
+	uris = append(uris, s.pkgs[dir]...)
+	s.pkgs[dir] = []string{uri}
+
+	for f := range byFile {
+		s.pkgs[dir] = append(s.pkgs[dir], lspURI(f))
+	}
+
+	uris = append(uris, s.pkgs[dir]...)
+	slices.Sort(uris)
+
+	for _, u := range slices.Compact(uris) {
+		if e := s.publish(u, byFile[lspPath(u)]); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (s *lspServer) diagnostic(f gocomplain.Finding) lspDiagnostic {
+	var d lspDiagnostic = lspDiagnostic{
+		Code:     f.Tool,
+		Message:  f.Msg,
+		Severity: lspWarning,
+		Source:   "gocomplain",
+	}
+	var lines []string = strings.Split(s.text(f.File), "\n")
+
+	switch f.Tool {
+	case "spellcheck":
+		_, d.Data = f.Correction()
+		d.Severity = lspInformation
+	case "gofmt", "line-length":
+		d.Severity = lspInformation
+	}
+
+	f.Line = max(f.Line, 1)
+	if f.Line <= len(lines) {
+		d.Range = lspRangeOf(f, lines[f.Line-1])
+	} else {
+		d.Range.Start.Line = f.Line - 1
+		d.Range.End.Line = f.Line - 1
+	}
+
+	return d
+}
+
+// document will track the provided document notification, and check
+// documents as they are opened or saved.
+func (s *lspServer) document(msg lspMsg) error {
+	var doc lspTextDoc
+	var e error
+
+	if msg.Method == "textDocument/didChange" {
+		if e = s.change(msg.Params); e != nil {
+			return s.invalid(msg, e)
+		}
+
+		return nil
+	}
+
+	if e = json.Unmarshal(msg.Params, &doc); e != nil {
+		return s.invalid(msg, e)
+	}
+
+	if msg.Method == "textDocument/didClose" {
+		delete(s.docs, lspPath(doc.TextDocument.URI))
+		return nil
+	}
+
+	if text := doc.TextDocument.Text; text != "" {
+		s.docs[lspPath(doc.TextDocument.URI)] = text
+	}
+
+	return s.check(doc.TextDocument.URI)
+}
+
+// fail will reply to the provided request with a JSON-RPC error.
+func (s *lspServer) fail(
+	id json.RawMessage, code int, msg string,
+) error {
+	return lspSend(
+		s.out,
+		map[string]any{
+			"error": map[string]any{"code": code, "message": msg},
+			"id":    id,
+		},
+	)
+}
+
+// format will return the edits needed to gofmt the provided document,
+// if any.
+func (s *lspServer) format(uri string) []lspTextEdit {
+	var b []byte
+	var e error
+	var fn string = lspPath(uri)
+	var text string = s.text(fn)
+	var lines []string = strings.Split(text, "\n")
+
+	if !strings.HasSuffix(fn, ".go") {
+		return nil
+	}
+
+	if b, e = gocomplain.GoFmtSource([]byte(text)); e != nil {
+		return nil
+	} else if string(b) == text {
+		return nil
+	}
+
+	return []lspTextEdit{
+		{
+			NewText: string(b),
+			Range: lspRange{
+				End: lspPosition{
+					Character: lspUTF16(lines[len(lines)-1]),
+					Line:      len(lines) - 1,
+				},
+			},
+		},
+	}
+}
+
+func (s *lspServer) handle(msg lspMsg) error {
+	var doc lspTextDoc
+	var e error
+	var result any
+
+	switch msg.Method {
+	case "initialize":
+		result = map[string]any{
+			"capabilities": map[string]any{
+				"codeActionProvider":         true,
+				"documentFormattingProvider": true,
+				"textDocumentSync": map[string]any{
+					"change":    1,
+					"openClose": true,
+					"save":      map[string]any{"includeText": true},
+				},
+			},
+			"serverInfo": map[string]any{
+				"name":    "gocomplain",
+				"version": gocomplain.Version,
+			},
+		}
+	case "shutdown":
+		result = nil
+	case "textDocument/codeAction":
+		if result, e = s.actions(msg.Params); e != nil {
+			return s.invalid(msg, e)
+		}
+	case "textDocument/didChange", "textDocument/didClose",
+		"textDocument/didOpen", "textDocument/didSave":
+		return s.document(msg)
+	case "textDocument/formatting":
+		if e = json.Unmarshal(msg.Params, &doc); e != nil {
+			return s.invalid(msg, e)
+		}
+
+		result = s.format(doc.TextDocument.URI)
+	default:
+		if msg.ID == nil {
+			return nil // Ignore unknown notifications
+		}
+
+		return s.fail(msg.ID, -32601, "method not found: "+msg.Method)
+	}
+
+	if msg.ID == nil {
+		return nil
+	}
+
+	return lspSend(
+		s.out,
+		map[string]any{"id": msg.ID, "result": result},
+	)
+}
+
+// invalid will reply to the provided request with an InvalidParams
+// error, rather than stopping the server. Notifications have no
+// reply, so they are dropped.
+func (s *lspServer) invalid(msg lspMsg, e error) error {
+	if msg.ID == nil {
+		return nil
+	}
+
+	return s.fail(msg.ID, -32602, "invalid LSP params: "+e.Error())
+}
+
+func (s *lspServer) publish(uri string, diags []lspDiagnostic) error {
+	if diags == nil {
+		diags = []lspDiagnostic{}
+	}
+
+	return lspSend(
+		s.out,
+		map[string]any{
+			"method": "textDocument/publishDiagnostics",
+			"params": map[string]any{
+				"diagnostics": diags,
+				"uri":         uri,
+			},
+		},
+	)
+}
+
+// text will return the contents of the provided file, preferring the
+// editor's copy, if open.
+func (s *lspServer) text(fn string) string {
+	var b []byte
+
+	if text, ok := s.docs[fn]; ok {
+		return text
+	}
+
+	b, _ = os.ReadFile(fn)
+
+	if !utf8.Valid(b) {
+		return ""
+	}
+
+	return string(b)
+}
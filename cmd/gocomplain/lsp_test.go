@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mjwhitta/gocomplain"
+)
+
+// lspPublished is a publishDiagnostics notification.
+type lspPublished struct {
+	Method string `json:"method"`
+	Params struct {
+		Diagnostics []lspDiagnostic `json:"diagnostics"`
+		URI         string          `json:"uri"`
+	} `json:"params"`
+}
+
+func TestLSPCheck(t *testing.T) {
+	var buf bytes.Buffer
+	var long string = "package a\n\n// " +
+		strings.Repeat("a", 80) + "\n"
+	var pub []lspPublished
+	var short string = "package a\n"
+	var srv *lspServer = &lspServer{
+		docs: map[string]string{},
+		out:  &buf,
+		pkgs: map[string][]string{},
+	}
+	var uri string
+
+	t.Chdir(t.TempDir())
+	lspTestGlobals(t)
+
+	uri = lspURI("a.go")
+
+	// Opening a file publishes its diagnostics
+	lspTestWrite(t, "a.go", long)
+	lspTestHandle(t, srv, "textDocument/didOpen", uri, long)
+
+	if pub = lspTestRead(t, &buf); len(pub) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(pub))
+	} else if pub[0].Params.URI != uri {
+		t.Errorf("got %s, want %s", pub[0].Params.URI, uri)
+	} else if len(pub[0].Params.Diagnostics) != 1 {
+		t.Fatalf("got %v", pub[0].Params.Diagnostics)
+	}
+
+	if d := pub[0].Params.Diagnostics[0]; d.Code != "line-length" {
+		t.Errorf("got code %s, want line-length", d.Code)
+	} else if d.Range.Start.Line != 2 {
+		t.Errorf("got line %d, want 2", d.Range.Start.Line)
+	}
+
+	// Saving a fixed file clears them
+	lspTestWrite(t, "a.go", short)
+	lspTestHandle(t, srv, "textDocument/didSave", uri, short)
+
+	if pub = lspTestRead(t, &buf); len(pub) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(pub))
+	} else if pub[0].Method != "textDocument/publishDiagnostics" {
+		t.Errorf("got method %s", pub[0].Method)
+	} else if len(pub[0].Params.Diagnostics) != 0 {
+		t.Errorf("got %v, want none", pub[0].Params.Diagnostics)
+	}
+}
+
+func TestLSPRangeOf(t *testing.T) {
+	var tests []struct {
+		col   int
+		end   int
+		msg   string
+		name  string
+		start int
+		text  string
+		tool  string
+	} = []struct {
+		col   int
+		end   int
+		msg   string
+		name  string
+		start int
+		text  string
+		tool  string
+	}{
+		{
+			end:   5,
+			name:  "no column",
+			start: 2,
+			text:  "\t\tfoo",
+			tool:  "line-length",
+		},
+		{
+			col:   5,
+			end:   4,
+			name:  "multibyte",
+			start: 3,
+			text:  "aé b",
+			tool:  "govet",
+		},
+		{
+			col:   6,
+			end:   4,
+			name:  "surrogate pair",
+			start: 3,
+			text:  "𝄞 x",
+			tool:  "govet",
+		},
+		{
+			col:   1,
+			end:   9,
+			msg:   "teh ==> the",
+			name:  "misspelling",
+			start: 6,
+			text:  "// 𝄞 teh",
+			tool:  "spellcheck",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				var f gocomplain.Finding = gocomplain.Finding{
+					Col:  test.col,
+					Line: 3,
+					Msg:  test.msg,
+					Tool: test.tool,
+				}
+				var r lspRange = lspRangeOf(f, test.text)
+
+				if (r.Start.Line != 2) || (r.End.Line != 2) {
+					t.Errorf(
+						"got lines %d-%d, want 2",
+						r.Start.Line,
+						r.End.Line,
+					)
+				}
+
+				if r.Start.Character != test.start {
+					t.Errorf(
+						"got start %d, want %d",
+						r.Start.Character,
+						test.start,
+					)
+				}
+
+				if r.End.Character != test.end {
+					t.Errorf(
+						"got end %d, want %d",
+						r.End.Character,
+						test.end,
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestLSPRead(t *testing.T) {
+	var tests []struct {
+		expected string
+		in       string
+		name     string
+	} = []struct {
+		expected string
+		in       string
+		name     string
+	}{
+		{
+			expected: "{}",
+			in: "Content-Length: 2\r\n" +
+				"Content-Type: a\r\n\r\n{}",
+			name: "headers",
+		},
+		{
+			in:   "Content-Type: a\r\n\r\n{}",
+			name: "missing length",
+		},
+		{
+			in:   "Content-Length: a\r\n\r\n{}",
+			name: "invalid length",
+		},
+		{
+			in:   "Content-Length: 10\r\n\r\n{}",
+			name: "short body",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				var b []byte
+				var e error
+
+				b, e = lspRead(
+					bufio.NewReader(strings.NewReader(test.in)),
+				)
+
+				if (e == nil) != (test.expected != "") {
+					t.Fatalf("got error %v", e)
+				}
+
+				if string(b) != test.expected {
+					t.Errorf("got %q, want %q", b, test.expected)
+				}
+			},
+		)
+	}
+}
+
+func TestLSPSend(t *testing.T) {
+	var b []byte
+	var buf bytes.Buffer
+	var e error
+	var msg map[string]any
+
+	e = lspSend(&buf, map[string]any{"id": 1, "result": "é"})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	// Content-Length counts bytes, not runes
+	if b, e = lspRead(bufio.NewReader(&buf)); e != nil {
+		t.Fatal(e)
+	}
+
+	if e = json.Unmarshal(b, &msg); e != nil {
+		t.Fatal(e)
+	}
+
+	if (msg["jsonrpc"] != "2.0") || (msg["result"] != "é") {
+		t.Errorf("got %v", msg)
+	}
+}
+
+// lspTestGlobals will configure the globals used by the LSP server to
+// only check line length, restoring them after the test.
+func lspTestGlobals(t *testing.T) {
+	var length uint = flags.length
+	var prevOSes []string = oses
+	var prevTools []string = tools
+
+	t.Cleanup(
+		func() {
+			flags.length = length
+			oses = prevOSes
+			tools = prevTools
+		},
+	)
+
+	flags.length = 70
+	oses = []string{runtime.GOOS}
+	tools = []string{"line-length"}
+}
+
+// lspTestHandle will send the provided notification for the provided
+// document to the server.
+func lspTestHandle(
+	t *testing.T,
+	srv *lspServer,
+	method string,
+	uri string,
+	text string,
+) {
+	var doc lspTextDoc
+	var e error
+	var msg lspMsg = lspMsg{Method: method}
+
+	t.Helper()
+
+	doc.TextDocument.Text = text
+	doc.TextDocument.URI = uri
+
+	if msg.Params, e = json.Marshal(doc); e != nil {
+		t.Fatal(e)
+	}
+
+	if e = srv.handle(msg); e != nil {
+		t.Fatal(e)
+	}
+}
+
+// lspTestRead will return the notifications the server has written
+// to the provided buffer, which is emptied.
+func lspTestRead(t *testing.T, buf *bytes.Buffer) []lspPublished {
+	var b []byte
+	var e error
+	var out []lspPublished
+	var pub lspPublished
+	var r *bufio.Reader = bufio.NewReader(buf)
+
+	t.Helper()
+
+	for {
+		if b, e = lspRead(r); e != nil {
+			return out
+		}
+
+		pub = lspPublished{}
+		if e = json.Unmarshal(b, &pub); e != nil {
+			t.Fatal(e)
+		}
+
+		out = append(out, pub)
+	}
+}
+
+// lspTestWrite will write the provided source to the provided file.
+func lspTestWrite(t *testing.T, fn string, src string) {
+	t.Helper()
+
+	if e := os.WriteFile(fn, []byte(src), 0o600); e != nil {
+		t.Fatal(e)
+	}
+}
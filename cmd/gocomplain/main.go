@@ -1,19 +1,28 @@
 package main
 
 import (
+	"cmp"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/mjwhitta/cli"
 	"github.com/mjwhitta/gocomplain"
+	hl "github.com/mjwhitta/hilighter"
 	"github.com/mjwhitta/log"
 	"github.com/mjwhitta/pathname"
+	"github.com/mjwhitta/where"
+	"golang.org/x/term"
 )
 
 var (
 	all []string = []string{
+		"build",
 		"gofmt",
 		"gofumpt",
 		"gocyclo",
@@ -23,13 +32,197 @@ var (
 		"line-length",
 		"spellcheck",
 		"staticcheck",
+		"unchecked",
 	}
-	inMod bool
-	oses  []string
-	rm    []string
-	tools []string
+	baseline []gocomplain.Finding
+	cgoEnv   map[string]string = map[string]string{
+		"CC":          os.Getenv("CC"),
+		"CGO_CFLAGS":  os.Getenv("CGO_CFLAGS"),
+		"CGO_LDFLAGS": os.Getenv("CGO_LDFLAGS"),
+		"CXX":         os.Getenv("CXX"),
+	}
+	browsing bool
+	curGOOS  string
+	curTags  string
+	dist     []string
+	findings []gocomplain.Finding
+	goEnv    map[string]string = map[string]string{
+		"CGO_ENABLED": os.Getenv("CGO_ENABLED"),
+		"GOARCH":      os.Getenv("GOARCH"),
+		"GOOS":        os.Getenv("GOOS"),
+	}
+	goflags  string = os.Getenv("GOFLAGS")
+	inMod    bool
+	merged   map[string]int = map[string]int{}
+	oses     []string
+	outputs  []reportOutput
+	rm       []string
+	serve    bool
+	tagSets  []string
+	tools    []string
+	watching bool
 )
 
+func cache(action string) {
+	var dir string
+	var e error
+	var entries uint
+	var size int64
+
+	switch action {
+	case "clean":
+		if e = gocomplain.CacheClean(); e != nil {
+			panic(e)
+		}
+
+		if !flags.quiet {
+			log.Good("Cache cleaned")
+		}
+	case "stats":
+		if dir, e = gocomplain.CacheDir(); e != nil {
+			panic(e)
+		}
+
+		if entries, size, e = gocomplain.CacheStats(); e != nil {
+			panic(e)
+		}
+
+		log.Infof("Location: %s", dir)
+		log.Infof("Entries:  %d", entries)
+		log.Infof("Size:     %d bytes", size)
+	}
+}
+
+// cgoVars will return the CGO environment for the provided target,
+// using the most specific configured mapping and falling back to the
+// original environment.
+func cgoVars(target string) map[string]string {
+	var goarch string
+	var goos string
+	var ok bool
+	var vars map[string]string = maps.Clone(cgoEnv)
+
+	goos, goarch = resolve(target)
+
+	for _, key := range []string{goos, goos + "/" + goarch} {
+		for k, v := range cfg.CGO[key] {
+			if _, ok = vars[k]; ok {
+				vars[k] = v
+			}
+		}
+	}
+
+	return vars
+}
+
+// checkCGO will return a message for each compiler configured for
+// the provided target that is not installed, if CGO is enabled. Such
+// targets are checked without CGO.
+func checkCGO(target string) []string {
+	var cmd []string
+	var out []string
+	var vars map[string]string
+
+	if !flags.cgo {
+		return nil
+	}
+
+	vars = cgoVars(target)
+
+	for _, k := range []string{"CC", "CXX"} {
+		if cmd = strings.Fields(vars[k]); len(cmd) == 0 {
+			continue
+		}
+
+		if where.Is(cmd[0]) == "" {
+			out = append(
+				out,
+				hl.Sprintf(
+					"%s %s for %s not found, checking without cgo",
+					k,
+					cmd[0],
+					target,
+				),
+			)
+		}
+	}
+
+	return out
+}
+
+// defaultArch will return the GOARCH to use when only a GOOS is
+// provided, preferring that of the original environment, then that
+// of the host.
+func defaultArch(goos string) string {
+	var arch string
+	var name string
+
+	for _, arch = range []string{goEnv["GOARCH"], runtime.GOARCH} {
+		if arch == "" {
+			continue
+		}
+
+		if slices.Contains(distList(), goos+"/"+arch) {
+			return arch
+		}
+	}
+
+	for _, target := range distList() {
+		if name, arch, _ = strings.Cut(target, "/"); name == goos {
+			return arch
+		}
+	}
+
+	return cmp.Or(goEnv["GOARCH"], runtime.GOARCH)
+}
+
+// dispatch will run the requested mode: serving diagnostics,
+// watching for changes, or a single run whose findings are browsed
+// or reported.
+func dispatch() error {
+	var e error
+	var other map[string][]string
+	var src map[string][]string
+	var tests map[string][]string
+
+	if serve {
+		return lsp()
+	} else if watching {
+		return watch()
+	}
+
+	src, tests, other = gocomplain.FindSrcFiles(".", flags.prune...)
+	run(src, tests, other)
+
+	if browsing {
+		return tui(findings)
+	} else if collecting() {
+		if e = report(findings); e != nil {
+			return e
+		}
+	}
+
+	if !flags.quiet {
+		log.Good("Done")
+	}
+
+	return nil
+}
+
+// distList will return the targets supported by the Go toolchain,
+// which are only looked up once.
+func distList() []string {
+	var e error
+
+	if dist == nil {
+		if dist, e = gocomplain.Targets(); e != nil {
+			dist = []string{}
+		}
+	}
+
+	return dist
+}
+
 func infof(str string, args ...any) {
 	if !flags.quiet {
 		log.Infof(str, args...)
@@ -38,6 +231,15 @@ func infof(str string, args ...any) {
 
 func isCmd(arg string) bool {
 	switch arg {
+	case "cache":
+		cache(cli.Arg(1))
+		return true
+	case "complexity":
+		if e := complexity(); e != nil {
+			log.ErrX(Exception, e.Error())
+		}
+
+		return true
 	case "i", "install", "u", "update", "upgrade":
 		gocomplain.UpdateInstall()
 		return true
@@ -58,6 +260,17 @@ func isOS(arg string) (bool, []string) {
 		return true, []string{"windows"}
 	}
 
+	// Anything that looks like GOOS/GOARCH is validated later
+	if strings.Contains(arg, "/") {
+		return true, []string{arg}
+	}
+
+	for _, target := range distList() {
+		if strings.HasPrefix(target, arg+"/") {
+			return true, []string{arg}
+		}
+	}
+
 	return false, nil
 }
 
@@ -69,6 +282,8 @@ func isRemove(arg string) (bool, string) {
 	arg = strings.TrimPrefix(arg, "no")
 
 	switch arg {
+	case "build":
+		return true, "build"
 	case "cyclo", "gocyclo":
 		return true, "gocyclo"
 	case "fmt", "gofmt":
@@ -87,15 +302,23 @@ func isRemove(arg string) (bool, string) {
 		return true, "spellcheck"
 	case "static", "staticcheck":
 		return true, "staticcheck"
+	case "unchecked":
+		return true, "unchecked"
 	}
 
 	return false, ""
 }
 
+func isSep(r rune) bool {
+	return (r == ',') || unicode.IsSpace(r)
+}
+
 func isTool(arg string) (bool, []string) {
 	switch arg {
 	case "all":
 		return true, all
+	case "build":
+		return true, []string{"build"}
 	case "cyclo", "gocyclo":
 		return true, []string{"gocyclo"}
 	case "fmt", "gofmt":
@@ -114,6 +337,8 @@ func isTool(arg string) (bool, []string) {
 		return true, []string{"spellcheck"}
 	case "static", "staticcheck":
 		return true, []string{"staticcheck"}
+	case "unchecked":
+		return true, []string{"unchecked"}
 	}
 
 	return false, nil
@@ -130,24 +355,51 @@ func main() {
 	}()
 
 	var e error
-	var other map[string][]string
-	var src map[string][]string
-	var tests map[string][]string
 
+	// Run by "go vet" to analyze a single package
+	gocomplain.AnalyzerMain()
+
+	// Parsed here, rather than in init, so tests can run
+	cli.Parse()
 	validate()
 	processConfig()
 
+	gocomplain.Cache = !flags.nocache
 	gocomplain.CGO = flags.cgo
 	gocomplain.Debug = flags.debug
+	gocomplain.DisplayWidth = flags.display
 	gocomplain.Quiet = flags.quiet
+	gocomplain.TabWidth = flags.tabwidth
 
 	if inMod, e = setup(); e != nil {
 		panic(e)
 	}
 
+	if baseline, e = gocomplain.ReadBaseline(baselineFile); e != nil {
+		panic(e)
+	}
+
+	parseArgs()
+	resolveTargets()
+	resolveTools()
+
+	if e = dispatch(); e != nil {
+		panic(e)
+	}
+}
+
+// parseArgs will sort the provided arguments into commands, modes,
+// targets, and tools. Commands are run immediately.
+func parseArgs() {
 	for _, arg := range cli.Args() {
 		if ok := isCmd(arg); ok {
 			os.Exit(Good)
+		} else if arg == "lsp" {
+			serve = true
+		} else if arg == "tui" {
+			browsing = true
+		} else if arg == "watch" {
+			watching = true
 		} else if ok, add := isOS(arg); ok {
 			oses = append(oses, add...)
 		} else if ok, add := isRemove(arg); ok {
@@ -158,164 +410,365 @@ func main() {
 			cli.Usage(InvalidArgument)
 		}
 	}
+}
 
-	if len(oses) == 0 {
-		oses = append(oses, runtime.GOOS)
-	}
-
-	if len(tools) == 0 {
-		tools = append(tools, all...)
-	}
+// processCGO will warn about cgo settings for unknown targets, and
+// unknown cgo variables, in the config.
+func processCGO() {
+	for _, target := range slices.Sorted(maps.Keys(cfg.CGO)) {
+		if !validTarget(target) {
+			log.Warnf("Unknown cgo target %s", target)
+		}
 
-	for i := range rm {
-		for j := range tools {
-			if tools[j] == rm[i] {
-				tools = append(tools[:j], tools[j+1:]...)
-				break
+		for _, k := range slices.Sorted(maps.Keys(cfg.CGO[target])) {
+			if _, ok := cgoEnv[k]; !ok {
+				log.Warnf("Unknown cgo variable %s for %s", k, target)
 			}
 		}
 	}
-
-	src, tests, other = gocomplain.FindSrcFiles(".", flags.prune...)
-	run(src, tests, other)
-
-	if !flags.quiet {
-		log.Good("Done")
-	}
 }
 
-func output(out []string) {
-	for _, ln := range out {
-		log.Warn(ln)
+func processConfig() {
+	if flags.cognitive == 15 {
+		flags.cognitive = cfg.Cognitive
 	}
-}
 
-func processConfig() {
 	if flags.confidence == 0.8 {
 		flags.confidence = cfg.Confidence
 	}
 
-	for _, ignore := range cfg.Ignore {
-		flags.ignore = append(flags.ignore, ignore)
-	}
+	processCGO()
+
+	flags.display = flags.display || cfg.Display
 
 	if flags.length == 70 {
 		flags.length = cfg.Length
 	}
 
+	processLists()
+
 	if flags.over == 15 {
 		flags.over = cfg.Over
 	}
 
+	flags.quiet = flags.quiet || cfg.Quiet
+
+	if flags.tabwidth == 4 {
+		flags.tabwidth = cfg.TabWidth
+	}
+
+	if flags.top == 10 {
+		flags.top = cfg.Top
+	}
+}
+
+// processLists will add the lists in the config to those provided as
+// flags, warning about unknown exemptions and lint rules.
+func processLists() {
+	for _, exempt := range cfg.Exempt {
+		if !slices.Contains(gocomplain.LineLengthExemptions, exempt) {
+			log.Warnf("Unknown line-length exemption %s", exempt)
+			continue
+		}
+
+		flags.exempt = append(flags.exempt, exempt)
+	}
+
+	for _, ignore := range cfg.Ignore {
+		flags.ignore = append(flags.ignore, ignore)
+	}
+
+	for name := range cfg.Lint {
+		if !slices.Contains(gocomplain.LintRules(), name) {
+			log.Warnf("Unknown lint rule %s", name)
+		}
+	}
+
 	for _, prune := range cfg.Prune {
 		flags.prune = append(flags.prune, prune)
 	}
 
-	flags.quiet = flags.quiet || cfg.Quiet
-
 	for _, skip := range cfg.Skip {
 		flags.skip = append(flags.skip, skip)
 	}
 }
 
+// resetGOOS will restore the environment the process started with.
+func resetGOOS() {
+	curGOOS = ""
+
+	for k, v := range goEnv {
+		if v == "" {
+			os.Unsetenv(k)
+		} else {
+			os.Setenv(k, v)
+		}
+	}
+
+	if flags.cgo {
+		for k, v := range cgoEnv {
+			os.Setenv(k, v)
+		}
+	}
+}
+
+// resolve will return the GOOS and GOARCH of the provided target.
+func resolve(target string) (string, string) {
+	var goarch string
+	var goos string
+
+	if goos, goarch, _ = strings.Cut(target, "/"); goarch == "" {
+		goarch = defaultArch(goos)
+	}
+
+	return goos, goarch
+}
+
+// resolveTargets will fill in the default targets and build tags,
+// and exit if any target is unsupported.
+func resolveTargets() {
+	if len(oses) == 0 {
+		oses = append(oses, cfg.Targets...)
+	}
+
+	for _, tags := range slices.Concat(flags.tags, cfg.Tags) {
+		// Tags may be separated by commas or spaces
+		tags = strings.Join(strings.FieldsFunc(tags, isSep), ",")
+
+		if (tags != "") && !slices.Contains(tagSets, tags) {
+			tagSets = append(tagSets, tags)
+		}
+	}
+
+	if len(oses) == 0 {
+		oses = append(oses, cmp.Or(goEnv["GOOS"], runtime.GOOS))
+	}
+
+	for _, target := range oses {
+		if !validTarget(target) {
+			log.ErrX(
+				InvalidArgument,
+				hl.Sprintf("Unsupported target %s", target),
+			)
+		}
+	}
+}
+
+// resolveTools will fill in the default tools, less any removed.
+func resolveTools() {
+	if len(tools) == 0 {
+		tools = append(tools, all...)
+	}
+
+	for i := range rm {
+		for j := range tools {
+			if tools[j] == rm[i] {
+				tools = append(tools[:j], tools[j+1:]...)
+				break
+			}
+		}
+	}
+}
+
 func run(src ...map[string][]string) {
 	var lineLength bool
 	var spellcheck bool
 
 	for _, goos := range oses {
 		infof("Setting GOOS to %s", goos)
-		os.Setenv("GOOS", goos)
-
-		if flags.cgo {
-			switch goos {
-			case "windows":
-				os.Setenv("CC", "x86_64-w64-mingw32-gcc")
-			default:
-				os.Setenv("CC", "")
+		setGOOS(goos)
+		output("cgo", checkCGO(goos))
+
+		for i := range len(tagSets) + 1 {
+			if i > 0 {
+				infof("Setting build tags to %s", tagSets[i-1])
+				setTags(tagSets[i-1])
 			}
-			os.Setenv("CGO_ENABLED", "1")
-		}
 
-		if ll, spell := runOS(src[:2]...); ll && spell {
-			lineLength = true
-			spellcheck = true
-		} else if ll {
-			lineLength = true
-		} else if spell {
-			spellcheck = true
+			if ll, spell := runOS(src[:2]...); ll && spell {
+				lineLength = true
+				spellcheck = true
+			} else if ll {
+				lineLength = true
+			} else if spell {
+				spellcheck = true
+			}
 		}
+
+		setTags("")
+	}
+
+	resetGOOS()
+
+	if slices.Contains(tools, "unchecked") {
+		infof("Checking for files excluded from every target...")
+		output("unchecked", unchecked(src[:2]...))
+	}
+
+	if lineLength && flags.fix {
+		infof("Fixing improper line-length...")
+		output(
+			"line-length",
+			gocomplain.LineLengthFix(
+				flags.length,
+				flags.exempt,
+				src[:2]...,
+			),
+		)
 	}
 
 	if lineLength {
 		infof("Checking for improper line-length...")
-		output(gocomplain.LineLength(flags.length, src[:2]...))
+		output(
+			"line-length",
+			gocomplain.LineLength(
+				flags.length,
+				flags.exempt,
+				src[:2]...,
+			),
+		)
 	}
 
-	if spellcheck {
-		os.Setenv("GOOS", runtime.GOOS)
-
-		infof("Checking spelling (misspell)...")
-		output(gocomplain.Misspell(flags.ignore, src...))
+	if spellcheck && flags.fix {
+		infof("Fixing spelling...")
+		output(
+			"spellcheck",
+			gocomplain.SpellFix(
+				flags.ignore,
+				flags.skip,
+				flags.fixExport,
+				spellChoose,
+				src...,
+			),
+		)
+	}
 
-		infof("Checking spelling (codespell)...")
+	if spellcheck {
+		infof("Checking spelling...")
 		output(
+			"spellcheck",
 			gocomplain.SpellCheck(flags.ignore, flags.skip, src...),
 		)
 	}
 }
 
 func runOS(src ...map[string][]string) (bool, bool) {
+	var analyzers []string
 	var lineLength bool
 	var spellcheck bool
 
 	for _, tool := range tools {
+		// Only some tools are affected by build tags
+		switch tool {
+		case "build", "govet", "ineffassign", "staticcheck":
+		default:
+			if curTags != "" {
+				continue
+			}
+		}
+
 		switch tool {
+		case "build":
+			subInfof("Compiling code (go build)...")
+			if inMod {
+				output(tool, gocomplain.GoBuild())
+			} else {
+				output(tool, gocomplain.GoBuild(src...))
+			}
 		case "gocyclo":
-			subInfof("Checking code complexity (gocyclo)...")
-			output(gocomplain.GoCyclo(flags.over))
+			subInfof("Checking code complexity...")
+			output(
+				tool,
+				gocomplain.GoCyclo(
+					flags.over,
+					flags.cognitive,
+					src...,
+				),
+			)
 		case "gofmt":
 			subInfof("Formatting code (gofmt)...")
-			output(gocomplain.GoFmt())
+			output(tool, gocomplain.GoFmt())
 		case "gofumpt":
 			subInfof("Optimizing code (gofumpt)...")
-			output(gocomplain.GoFumpt())
+			output(tool, gocomplain.GoFumpt())
 		case "golint":
-			subInfof("Linting code (golint)...")
-			output(gocomplain.GoLint(flags.confidence))
-		case "govet":
-			subInfof("Vetting code (go vet)...")
-			if inMod {
-				output(gocomplain.GoVet())
-			} else {
-				output(gocomplain.GoVet(src...))
-			}
-		case "ineffassign":
-			subInfof(
-				"Looking for inefficient assignments (%s)...",
+			subInfof("Linting code...")
+			output(
 				tool,
+				gocomplain.Lint(flags.confidence, cfg.Lint, src...),
 			)
-			if inMod {
-				output(gocomplain.IneffAssign())
-			} else {
-				output(gocomplain.IneffAssign(src...))
-			}
+		case "govet", "ineffassign", "staticcheck":
+			analyzers = append(analyzers, tool)
 		case "line-length":
 			lineLength = true
 		case "spellcheck":
 			spellcheck = true
-		case "staticcheck":
-			subInfof("Running static analysis (staticcheck)...")
-			if inMod {
-				output(gocomplain.StaticCheck())
-			} else {
-				output(gocomplain.StaticCheck(src...))
-			}
+		}
+	}
+
+	// Packages are loaded once for all in-process analyzers
+	if len(analyzers) > 0 {
+		subInfof(
+			"Analyzing code (%s)...",
+			strings.Join(analyzers, ", "),
+		)
+
+		if inMod {
+			outputFindings(gocomplain.Analyze(analyzers))
+		} else {
+			outputFindings(gocomplain.Analyze(analyzers, src...))
 		}
 	}
 
 	return lineLength, spellcheck
 }
 
+// setGOOS will configure the environment for the provided target,
+// which is either a GOOS or a GOOS/GOARCH pair.
+func setGOOS(target string) {
+	var goarch string
+	var goos string
+
+	goos, goarch = resolve(target)
+
+	curGOOS = target
+	os.Setenv("GOARCH", goarch)
+	os.Setenv("GOOS", goos)
+
+	if !flags.cgo {
+		return
+	}
+
+	// Without its cross compilers, a target can only be checked
+	// without CGO
+	if len(checkCGO(target)) > 0 {
+		os.Setenv("CGO_ENABLED", "0")
+		return
+	}
+
+	for k, v := range cgoVars(target) {
+		os.Setenv(k, v)
+	}
+
+	os.Setenv("CGO_ENABLED", "1")
+}
+
+// setTags will configure the environment so that tools which load
+// packages use the provided build tags.
+func setTags(tags string) {
+	curTags = tags
+
+	if tags == "" {
+		os.Setenv("GOFLAGS", goflags)
+		return
+	}
+
+	os.Setenv(
+		"GOFLAGS",
+		strings.TrimSpace(goflags+" -tags="+tags),
+	)
+}
+
 func setup() (bool, error) {
 	var cwd string
 	var e error
@@ -353,8 +806,84 @@ func setup() (bool, error) {
 	return true, nil
 }
 
+// spellChoose will prompt the user to choose between the fixes for
+// the provided misspelling. Nothing is chosen if stdin isn't a
+// terminal, or the user just hits enter.
+func spellChoose(f gocomplain.Finding, fixes []string) string {
+	var b []byte = make([]byte, 1)
+	var choice int
+	var e error
+	var line []byte
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return ""
+	}
+
+	hl.Printf("%s\n", f.String())
+	for i, fix := range fixes {
+		hl.Printf("  %d: %s\n", i+1, fix)
+	}
+	hl.Printf("Choose a fix (enter to skip): ")
+
+	// Read a whole line, so nothing is left for the next prompt
+	for {
+		if n, e := os.Stdin.Read(b); (n == 0) || (e != nil) {
+			break
+		} else if b[0] == '\n' {
+			break
+		}
+
+		line = append(line, b[0])
+	}
+
+	choice, e = strconv.Atoi(strings.TrimSpace(string(line)))
+	if (e != nil) || (choice < 1) || (choice > len(fixes)) {
+		return ""
+	}
+
+	return fixes[choice-1]
+}
+
 func subInfof(str string, args ...any) {
 	if !flags.quiet {
 		log.SubInfof(str, args...)
 	}
 }
+
+// unchecked will return the Go files that are excluded from every
+// target and build tag set in the matrix.
+func unchecked(src ...map[string][]string) []string {
+	var goarch string
+	var goos string
+	var targets []string
+
+	for _, target := range oses {
+		goos, goarch = resolve(target)
+		targets = append(targets, goos+"/"+goarch)
+	}
+
+	return gocomplain.Unchecked(
+		targets,
+		slices.Concat([]string{""}, tagSets),
+		src...,
+	)
+}
+
+// validTarget will return whether or not the Go toolchain supports
+// the provided GOOS or GOOS/GOARCH pair. If the supported targets
+// can't be determined, everything is assumed valid.
+func validTarget(target string) bool {
+	if len(distList()) == 0 {
+		return true
+	} else if strings.Contains(target, "/") {
+		return slices.Contains(distList(), target)
+	}
+
+	for _, supported := range distList() {
+		if strings.HasPrefix(supported, target+"/") {
+			return true
+		}
+	}
+
+	return false
+}
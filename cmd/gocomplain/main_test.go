@@ -0,0 +1,249 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestResetGOOS(t *testing.T) {
+	var tests map[string]string = map[string]string{
+		"CC":          "cc",
+		"CGO_CFLAGS":  "-O2",
+		"CGO_ENABLED": "",
+		"CGO_LDFLAGS": "",
+		"CXX":         "",
+		"GOARCH":      "",
+		"GOOS":        "plan9",
+	}
+
+	mainTestGlobals(t)
+
+	for k, v := range tests {
+		t.Setenv(k, v)
+
+		if _, ok := goEnv[k]; ok {
+			goEnv[k] = v
+		} else {
+			cgoEnv[k] = v
+		}
+	}
+
+	setGOOS("linux")
+	resetGOOS()
+
+	for k, v := range tests {
+		t.Run(
+			k,
+			func(t *testing.T) {
+				var actual string
+				var isGo bool
+				var ok bool
+
+				actual, ok = os.LookupEnv(k)
+				_, isGo = goEnv[k]
+
+				if actual != v {
+					t.Errorf("got %q, want %q", actual, v)
+				}
+
+				// Go variables that weren't set are removed
+				if ok && isGo && (v == "") {
+					t.Errorf("%s is set, want unset", k)
+				}
+			},
+		)
+	}
+
+	if curGOOS != "" {
+		t.Errorf("got %q, want none", curGOOS)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	var tests []struct {
+		arch   string
+		goos   string
+		target string
+	} = []struct {
+		arch   string
+		goos   string
+		target string
+	}{
+		{arch: "arm64", goos: "linux", target: "linux"},
+		{arch: "amd64", goos: "linux", target: "linux/amd64"},
+		{arch: "amd64", goos: "windows", target: "windows"},
+	}
+
+	mainTestGlobals(t)
+	goEnv["GOARCH"] = "arm64"
+
+	for _, test := range tests {
+		t.Run(
+			test.target,
+			func(t *testing.T) {
+				var goarch string
+				var goos string
+
+				goos, goarch = resolve(test.target)
+
+				if (goos != test.goos) || (goarch != test.arch) {
+					t.Errorf(
+						"got %s/%s, want %s/%s",
+						goos,
+						goarch,
+						test.goos,
+						test.arch,
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestSetGOOS(t *testing.T) {
+	var tests []struct {
+		cc     string
+		cgo    bool
+		enable string
+		goarch string
+		goos   string
+		target string
+	} = []struct {
+		cc     string
+		cgo    bool
+		enable string
+		goarch string
+		goos   string
+		target string
+	}{
+		{
+			cc:     "go",
+			cgo:    true,
+			enable: "1",
+			goarch: "amd64",
+			goos:   "linux",
+			target: "linux/amd64",
+		},
+		{
+			cc:     "gocomplain-missing-cc",
+			cgo:    true,
+			enable: "0",
+			goarch: "amd64",
+			goos:   "windows",
+			target: "windows",
+		},
+		{
+			cc:     "gocomplain-missing-cc",
+			enable: "unchanged",
+			goarch: "amd64",
+			goos:   "windows",
+			target: "windows",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.target,
+			func(t *testing.T) {
+				var vars map[string]string
+
+				mainTestGlobals(t)
+				t.Setenv("CC", "")
+				t.Setenv("CGO_ENABLED", "unchanged")
+				t.Setenv("GOARCH", "")
+				t.Setenv("GOOS", "")
+
+				cfg.CGO = cgoMap{test.goos: {"CC": test.cc}}
+				flags.cgo = test.cgo
+
+				setGOOS(test.target)
+
+				vars = map[string]string{
+					"CGO_ENABLED": test.enable,
+					"GOARCH":      test.goarch,
+					"GOOS":        test.goos,
+				}
+
+				// CC is only used if it's installed
+				if test.enable == "1" {
+					vars["CC"] = test.cc
+				}
+
+				for k, v := range vars {
+					if actual := os.Getenv(k); actual != v {
+						t.Errorf("got %s=%q, want %q", k, actual, v)
+					}
+				}
+
+				if curGOOS != test.target {
+					t.Errorf("got %s, want %s", curGOOS, test.target)
+				}
+			},
+		)
+	}
+}
+
+func TestValidTarget(t *testing.T) {
+	var tests map[string]bool = map[string]bool{
+		"linux":       true,
+		"linux/386":   false,
+		"linux/arm64": true,
+		"plan9":       false,
+		"windows":     true,
+	}
+
+	mainTestGlobals(t)
+
+	for target, expected := range tests {
+		t.Run(
+			target,
+			func(t *testing.T) {
+				if actual := validTarget(target); actual != expected {
+					t.Errorf("got %t, want %t", actual, expected)
+				}
+			},
+		)
+	}
+
+	// Everything is valid if the targets are unknown
+	dist = []string{}
+
+	if !validTarget("plan9") {
+		t.Errorf("got false, want true")
+	}
+}
+
+// mainTestGlobals will configure the globals used to select targets,
+// restoring them after the test.
+func mainTestGlobals(t *testing.T) {
+	var prevCGO cgoMap = cfg.CGO
+	var prevCGOEnv map[string]string = cgoEnv
+	var prevDist []string = dist
+	var prevEnabled bool = flags.cgo
+	var prevGOEnv map[string]string = goEnv
+
+	t.Cleanup(
+		func() {
+			cfg.CGO = prevCGO
+			cgoEnv = prevCGOEnv
+			curGOOS = ""
+			dist = prevDist
+			flags.cgo = prevEnabled
+			goEnv = prevGOEnv
+		},
+	)
+
+	cgoEnv = map[string]string{
+		"CC":          "",
+		"CGO_CFLAGS":  "",
+		"CGO_LDFLAGS": "",
+		"CXX":         "",
+	}
+	dist = []string{"linux/amd64", "linux/arm64", "windows/amd64"}
+	flags.cgo = true
+	goEnv = map[string]string{
+		"CGO_ENABLED": "",
+		"GOARCH":      "",
+		"GOOS":        "",
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mjwhitta/gocomplain"
+	hl "github.com/mjwhitta/hilighter"
+)
+
+// mdDetails will write a collapsible section listing the findings
+// for the provided tool.
+func mdDetails(
+	sb *strings.Builder, tool string, found []gocomplain.Finding,
+) {
+	var link string
+	var n int
+
+	for _, f := range found {
+		if f.Tool == tool {
+			n++
+		}
+	}
+
+	sb.WriteString(
+		hl.Sprintf(
+			"\n<details>\n<summary>%s (%d)</summary>\n\n",
+			tool,
+			n,
+		),
+	)
+
+	for _, f := range found {
+		if f.Tool != tool {
+			continue
+		}
+
+		sb.WriteString("- ")
+
+		if link = mdLink(f); link != "" {
+			sb.WriteString(link + ": ")
+		}
+
+		sb.WriteString(mdEscape(f.Msg + labels(f)))
+
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n</details>\n")
+}
+
+// mdEscape will escape characters that would otherwise break
+// Markdown tables or formatting.
+func mdEscape(str string) string {
+	return strings.NewReplacer(
+		"|", "\\|",
+		"<", "&lt;",
+		">", "&gt;",
+		"*", "\\*",
+		"_", "\\_",
+		"`", "\\`",
+	).Replace(str)
+}
+
+// mdLink will return a link to the provided finding's location,
+// relative to the module root.
+func mdLink(f gocomplain.Finding) string {
+	var fn string = filepath.ToSlash(f.File)
+
+	if fn == "" {
+		return ""
+	} else if f.Line < 1 {
+		return hl.Sprintf("[%s](%s)", mdEscape(fn), mdPath(fn))
+	}
+
+	return hl.Sprintf(
+		"[%s:%d](%s#L%d)",
+		mdEscape(fn),
+		f.Line,
+		mdPath(fn),
+		f.Line,
+	)
+}
+
+// mdPath will escape each segment of the provided slash-separated
+// path, so it can be used as a link target.
+func mdPath(fn string) string {
+	var segments []string = strings.Split(fn, "/")
+
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// mdSummary will write a table of finding counts per tool and GOOS.
+func mdSummary(
+	sb *strings.Builder, tools []string, found []gocomplain.Finding,
+) {
+	var counts map[string]map[string]int = map[string]map[string]int{}
+	var goos []string
+	var targets []string
+
+	for _, f := range found {
+		if counts[f.Tool] == nil {
+			counts[f.Tool] = map[string]int{}
+		}
+
+		counts[f.Tool][""]++
+
+		if goos = f.GOOS; len(goos) == 0 {
+			goos = []string{"any"}
+		}
+
+		for _, name := range goos {
+			counts[f.Tool][name]++
+
+			if !slices.Contains(targets, name) {
+				targets = append(targets, name)
+			}
+		}
+	}
+
+	slices.Sort(targets)
+
+	sb.WriteString("| Tool | Total |")
+	for _, name := range targets {
+		sb.WriteString(hl.Sprintf(" %s |", name))
+	}
+
+	sb.WriteString("\n| --- | ---: |")
+	sb.WriteString(strings.Repeat(" ---: |", len(targets)))
+	sb.WriteString("\n")
+
+	for _, tool := range tools {
+		sb.WriteString(
+			hl.Sprintf("| %s | %d |", tool, counts[tool][""]),
+		)
+
+		for _, name := range targets {
+			sb.WriteString(hl.Sprintf(" %d |", counts[tool][name]))
+		}
+
+		sb.WriteString("\n")
+	}
+}
+
+func writeMarkdown(w io.Writer, found []gocomplain.Finding) error {
+	var e error
+	var sb strings.Builder
+	var tools []string
+
+	found = sortFindings(found)
+
+	for _, f := range found {
+		if !slices.Contains(tools, f.Tool) {
+			tools = append(tools, f.Tool)
+		}
+	}
+
+	sb.WriteString("## GoComplain\n\n")
+
+	if len(found) == 0 {
+		sb.WriteString("No findings\n")
+	} else {
+		mdSummary(&sb, tools, found)
+	}
+
+	for _, tool := range tools {
+		mdDetails(&sb, tool, found)
+	}
+
+	if _, e = io.WriteString(w, sb.String()); e != nil {
+		return fmt.Errorf("failed to write Markdown report: %w", e)
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mjwhitta/gocomplain"
+)
+
+func TestMDLink(t *testing.T) {
+	var tests []struct {
+		expected string
+		file     string
+		line     int
+	} = []struct {
+		expected string
+		file     string
+		line     int
+	}{
+		{expected: "[a/b.go:3](a/b.go#L3)", file: "a/b.go", line: 3},
+		{expected: "[a.go](a.go)", file: "a.go"},
+		{
+			expected: "[my dir/a b.go:1](my%20dir/a%20b.go#L1)",
+			file:     "my dir/a b.go",
+			line:     1,
+		},
+		{
+			expected: "[a/\\_(x).go:2](a/_%28x%29.go#L2)",
+			file:     "a/_(x).go",
+			line:     2,
+		},
+		{
+			expected: "[a/b#c?.go](a/b%23c%3F.go)",
+			file:     "a/b#c?.go",
+		},
+		{file: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.file,
+			func(t *testing.T) {
+				var actual string = mdLink(
+					gocomplain.Finding{
+						File: test.file,
+						Line: test.line,
+					},
+				)
+
+				if actual != test.expected {
+					t.Errorf("got %q, want %q", actual, test.expected)
+				}
+			},
+		)
+	}
+}
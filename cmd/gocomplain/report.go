@@ -0,0 +1,287 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mjwhitta/gocomplain"
+	hl "github.com/mjwhitta/hilighter"
+	"github.com/mjwhitta/log"
+)
+
+// Findings can be suppressed by listing them in this file
+const baselineFile string = ".gocomplain-baseline"
+
+// Findings are written to each of these after all tools have run
+type reportOutput struct {
+	format string
+	path   string
+}
+
+// collect will add the provided finding to those already found, or
+// merge its GOOS and tags with an identical one from a previous
+// pass. It returns whether or not the finding is new.
+func collect(f gocomplain.Finding) bool {
+	var i int
+	var key string = quickfix(f)
+	var ok bool
+
+	if i, ok = merged[key]; !ok {
+		merged[key] = len(findings)
+		findings = append(findings, f)
+		return true
+	}
+
+	for _, goos := range f.GOOS {
+		if !slices.Contains(findings[i].GOOS, goos) {
+			findings[i].GOOS = append(findings[i].GOOS, goos)
+		}
+	}
+
+	// Findings from untagged passes aren't specific to any tags
+	if (findings[i].Tags == nil) || (f.Tags == nil) {
+		findings[i].Tags = nil
+		return false
+	}
+
+	for _, tags := range f.Tags {
+		if !slices.Contains(findings[i].Tags, tags) {
+			findings[i].Tags = append(findings[i].Tags, tags)
+		}
+	}
+
+	return false
+}
+
+// collecting will return whether findings are being gathered for
+// later, in addition to or rather than printed as they are found.
+// Findings from multiple GOOS passes are gathered so that they can
+// be merged.
+func collecting() bool {
+	return browsing || (len(outputs) > 0) || (passes() > 1)
+}
+
+// labels will return the GOOS values and tags the provided finding
+// appeared under, formatted for appending to its message.
+func labels(f gocomplain.Finding) string {
+	var tmp []string
+
+	if len(f.GOOS) > 0 {
+		tmp = append(tmp, strings.Join(f.GOOS, ", "))
+	}
+
+	if len(f.Tags) > 0 {
+		tmp = append(tmp, "tags: "+strings.Join(f.Tags, " or "))
+	}
+
+	if len(tmp) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(tmp, "; ") + ")"
+}
+
+// live will return whether the provided format is printed as
+// findings are found, rather than written after all tools have run.
+func live(format string) bool {
+	switch format {
+	case "quickfix", "text":
+		return true
+	}
+
+	return false
+}
+
+func output(tool string, out []string) {
+	for _, ln := range out {
+		outputFinding(gocomplain.ParseFinding(tool, ln), ln)
+	}
+}
+
+// outputFinding will collect or print the provided finding, which
+// was parsed from the provided line of output.
+func outputFinding(f gocomplain.Finding, ln string) {
+	if curGOOS != "" {
+		f.GOOS = []string{curGOOS}
+	}
+
+	if curTags != "" {
+		f.Tags = []string{curTags}
+	}
+
+	if f.Ignored(baseline) {
+		return
+	}
+
+	if collecting() && !collect(f) {
+		return
+	}
+
+	// Wait for all passes, so each finding prints once
+	if browsing || (passes() > 1) {
+		return
+	}
+
+	switch flags.format {
+	case "quickfix":
+		hl.Printf("%s\n", quickfix(f))
+	case "text":
+		log.Warn(ln)
+	}
+}
+
+// outputFindings will collect or print the provided findings.
+func outputFindings(found []gocomplain.Finding) {
+	for _, f := range found {
+		outputFinding(f, f.String())
+	}
+}
+
+// parseOutput will return the report described by the provided
+// "format=path" string. A bare path uses the --format flag.
+func parseOutput(str string) reportOutput {
+	var format string
+	var path string
+
+	if format, path, _ = strings.Cut(str, "="); path == "" {
+		return reportOutput{format: flags.format, path: str}
+	}
+
+	return reportOutput{format: format, path: path}
+}
+
+// passes will return how many times the per-OS tools are run.
+func passes() int {
+	return len(oses) * (len(tagSets) + 1)
+}
+
+// quickfix will normalize the provided finding to the
+// "file:line:col: msg" format understood by vim and emacs.
+func quickfix(f gocomplain.Finding) string {
+	if f.File == "" {
+		return hl.Sprintf("[%s] %s", f.Tool, f.Msg)
+	}
+
+	return hl.Sprintf(
+		"%s:%d:%d: [%s] %s",
+		f.File,
+		max(f.Line, 1),
+		max(f.Col, 1),
+		f.Tool,
+		f.Msg,
+	)
+}
+
+// report will write the collected findings to each requested
+// output.
+func report(found []gocomplain.Finding) error {
+	var e error
+
+	if live(flags.format) && (passes() > 1) {
+		for _, f := range found {
+			switch flags.format {
+			case "quickfix":
+				hl.Printf("%s\n", quickfix(f)+labels(f))
+			case "text":
+				log.Warn(quickfix(f) + labels(f))
+			}
+		}
+	}
+
+	for _, o := range outputs {
+		if e = writeOutput(o, found); e != nil {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// severity will return how serious the provided finding is, using
+// the GitLab Code Quality levels: major, minor, or info.
+func severity(f gocomplain.Finding) string {
+	switch f.Tool {
+	case "build", "cgo", "govet", "staticcheck":
+		return "major"
+	case "spellcheck":
+		return "info"
+	}
+
+	return "minor"
+}
+
+// sortFindings will return the provided findings ordered by tool,
+// file, GOOS, then position.
+func sortFindings(found []gocomplain.Finding) []gocomplain.Finding {
+	found = slices.Clone(found)
+
+	slices.SortStableFunc(
+		found,
+		func(a gocomplain.Finding, b gocomplain.Finding) int {
+			return cmp.Or(
+				cmp.Compare(a.Tool, b.Tool),
+				cmp.Compare(a.File, b.File),
+				slices.Compare(a.GOOS, b.GOOS),
+				cmp.Compare(a.Line, b.Line),
+				cmp.Compare(a.Col, b.Col),
+			)
+		},
+	)
+
+	return found
+}
+
+// writeOutput will write the provided findings to the provided
+// output, or stdout if it has no path.
+func writeOutput(o reportOutput, found []gocomplain.Finding) error {
+	var e error
+	var f *os.File
+	var w io.Writer = os.Stdout
+
+	if o.path != "" {
+		if f, e = os.Create(o.path); e != nil {
+			return fmt.Errorf("failed to create %s: %w", o.path, e)
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	switch o.format {
+	case "github":
+		return writeGitHub(w, found)
+	case "gitlab":
+		return writeGitLab(w, found)
+	case "html":
+		return writeHTML(w, found)
+	case "junit":
+		return writeJUnit(w, found)
+	case "markdown":
+		return writeMarkdown(w, found)
+	case "quickfix", "text":
+		return writeQuickfix(w, found)
+	case "sarif":
+		return writeSARIF(w, found)
+	}
+
+	return nil
+}
+
+func writeQuickfix(w io.Writer, found []gocomplain.Finding) error {
+	var e error
+	var sb strings.Builder
+
+	for _, f := range sortFindings(found) {
+		sb.WriteString(quickfix(f) + "\n")
+	}
+
+	if _, e = io.WriteString(w, sb.String()); e != nil {
+		return fmt.Errorf("failed to write findings: %w", e)
+	}
+
+	return nil
+}
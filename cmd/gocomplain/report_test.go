@@ -0,0 +1,106 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mjwhitta/gocomplain"
+)
+
+func TestCollect(t *testing.T) {
+	var tests []struct {
+		found []gocomplain.Finding
+		goos  []string
+		name  string
+		tags  []string
+	} = []struct {
+		found []gocomplain.Finding
+		goos  []string
+		name  string
+		tags  []string
+	}{
+		{
+			found: []gocomplain.Finding{
+				{GOOS: []string{"linux"}},
+				{GOOS: []string{"windows"}},
+				{GOOS: []string{"linux"}},
+			},
+			goos: []string{"linux", "windows"},
+			name: "merges GOOS",
+		},
+		{
+			found: []gocomplain.Finding{
+				{GOOS: []string{"linux"}, Tags: []string{"a"}},
+				{GOOS: []string{"linux"}, Tags: []string{"b"}},
+			},
+			goos: []string{"linux"},
+			name: "merges tags",
+			tags: []string{"a", "b"},
+		},
+		{
+			found: []gocomplain.Finding{
+				{GOOS: []string{"linux"}, Tags: []string{"a"}},
+				{GOOS: []string{"linux"}},
+				{GOOS: []string{"linux"}, Tags: []string{"b"}},
+			},
+			goos: []string{"linux"},
+			name: "untagged drops tags",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				var f gocomplain.Finding
+
+				findings = nil
+				merged = map[string]int{}
+
+				for i := range test.found {
+					f = test.found[i]
+					f.File = "a.go"
+					f.Line = 1
+					f.Msg = "bad"
+					f.Tool = "govet"
+
+					if collect(f) != (i == 0) {
+						t.Errorf("finding %d: wrong newness", i)
+					}
+				}
+
+				if len(findings) != 1 {
+					t.Fatalf("got %d findings, want 1", len(findings))
+				}
+
+				if !slices.Equal(findings[0].GOOS, test.goos) {
+					t.Errorf("got GOOS %v", findings[0].GOOS)
+				}
+
+				if !slices.Equal(findings[0].Tags, test.tags) {
+					t.Errorf("got tags %v", findings[0].Tags)
+				}
+			},
+		)
+	}
+}
+
+func TestCollectDistinct(t *testing.T) {
+	var found []gocomplain.Finding = []gocomplain.Finding{
+		gocomplain.ParseFinding("govet", "a.go:1:2: bad"),
+		gocomplain.ParseFinding("govet", "a.go:1:3: bad"),
+		gocomplain.ParseFinding("golint", "a.go:1:2: bad"),
+		gocomplain.ParseFinding("govet", "a.go:1:2: bad"),
+	}
+
+	findings = nil
+	merged = map[string]int{}
+
+	for _, f := range found {
+		collect(f)
+	}
+
+	if len(findings) != 3 {
+		t.Errorf("got %d findings, want 3", len(findings))
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+
+	"github.com/mjwhitta/gocomplain"
+)
+
+// Project page referenced by SARIF reports
+const sarifInfo string = "https://github.com/mjwhitta/gocomplain"
+
+type sarifDriver struct {
+	InformationURI string      `json:"informationUri"`
+	Name           string      `json:"name"`
+	Rules          []sarifRule `json:"rules"`
+	Version        string      `json:"version"`
+}
+
+type sarifLocation struct {
+	Physical sarifPhysical `json:"physicalLocation"`
+}
+
+type sarifLog struct {
+	Runs    []sarifRun `json:"runs"`
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifPhysical struct {
+	Artifact sarifURI     `json:"artifactLocation"`
+	Region   *sarifRegion `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartColumn int `json:"startColumn,omitempty"`
+	StartLine   int `json:"startLine"`
+}
+
+type sarifResult struct {
+	Level     string          `json:"level"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+	Message   sarifMessage    `json:"message"`
+	RuleID    string          `json:"ruleId"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifRun struct {
+	Results []sarifResult `json:"results"`
+	Tool    sarifTool     `json:"tool"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifURI struct {
+	URI string `json:"uri"`
+}
+
+// sarifLevel will return the SARIF level of the provided finding:
+// error, warning, or note.
+func sarifLevel(f gocomplain.Finding) string {
+	switch severity(f) {
+	case "major":
+		return "error"
+	case "minor":
+		return "warning"
+	}
+
+	return "note"
+}
+
+// sarifOf will return the provided finding as a SARIF result.
+func sarifOf(f gocomplain.Finding) sarifResult {
+	var r sarifResult = sarifResult{
+		Level:   sarifLevel(f),
+		Message: sarifMessage{Text: f.Msg + labels(f)},
+		RuleID:  f.Tool,
+	}
+
+	if f.File == "" {
+		return r
+	}
+
+	r.Locations = []sarifLocation{
+		{
+			Physical: sarifPhysical{
+				Artifact: sarifURI{URI: filepath.ToSlash(f.File)},
+			},
+		},
+	}
+
+	if f.Line > 0 {
+		r.Locations[0].Physical.Region = &sarifRegion{
+			StartColumn: f.Col,
+			StartLine:   f.Line,
+		}
+	}
+
+	return r
+}
+
+func writeSARIF(w io.Writer, found []gocomplain.Finding) error {
+	var e error
+	var enc *json.Encoder = json.NewEncoder(w)
+	var run sarifRun = sarifRun{
+		Results: []sarifResult{},
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				InformationURI: sarifInfo,
+				Name:           "gocomplain",
+				Rules:          []sarifRule{},
+				Version:        gocomplain.Version,
+			},
+		},
+	}
+	var seen []string
+
+	for _, f := range sortFindings(found) {
+		if !slices.Contains(seen, f.Tool) {
+			seen = append(seen, f.Tool)
+			run.Tool.Driver.Rules = append(
+				run.Tool.Driver.Rules,
+				sarifRule{ID: f.Tool},
+			)
+		}
+
+		run.Results = append(run.Results, sarifOf(f))
+	}
+
+	enc.SetIndent("", "  ")
+
+	e = enc.Encode(
+		sarifLog{
+			Runs:    []sarifRun{run},
+			Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+			Version: "2.1.0",
+		},
+	)
+	if e != nil {
+		return fmt.Errorf("failed to write SARIF report: %w", e)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/mjwhitta/gocomplain"
+)
+
+func TestSARIFLevel(t *testing.T) {
+	var tests map[string]string = map[string]string{
+		"build":       "error",
+		"gofmt":       "warning",
+		"golint":      "warning",
+		"govet":       "error",
+		"spellcheck":  "note",
+		"staticcheck": "error",
+	}
+
+	for tool, expected := range tests {
+		t.Run(
+			tool,
+			func(t *testing.T) {
+				var f gocomplain.Finding = gocomplain.Finding{
+					Tool: tool,
+				}
+				var level string = sarifLevel(f)
+
+				if level != expected {
+					t.Errorf("got %s, want %s", level, expected)
+				}
+			},
+		)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	var e error
+	var expected []string = []string{"build", "govet", "spellcheck"}
+	var found []gocomplain.Finding = []gocomplain.Finding{
+		{Col: 2, File: "a.go", Line: 3, Msg: "bad", Tool: "govet"},
+		{
+			File: "b.go",
+			Line: 1,
+			Msg:  "teh ==> the",
+			Tool: "spellcheck",
+		},
+		{Col: 1, File: "a.go", Line: 1, Msg: "worse", Tool: "govet"},
+		{Msg: "no position", Tool: "build"},
+	}
+	var log sarifLog
+	var results []sarifResult
+	var rules []string
+	var valid []string = []string{"error", "none", "note", "warning"}
+
+	if e = writeSARIF(&buf, found); e != nil {
+		t.Fatal(e)
+	}
+
+	if e = json.Unmarshal(buf.Bytes(), &log); e != nil {
+		t.Fatalf("invalid JSON: %s", e)
+	}
+
+	if log.Version != "2.1.0" {
+		t.Errorf("got version %s, want 2.1.0", log.Version)
+	}
+
+	if len(log.Runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(log.Runs))
+	}
+
+	for _, rule := range log.Runs[0].Tool.Driver.Rules {
+		rules = append(rules, rule.ID)
+	}
+
+	if !slices.Equal(rules, expected) {
+		t.Errorf("got rules %v", rules)
+	}
+
+	if results = log.Runs[0].Results; len(results) != len(found) {
+		t.Fatalf("got %d results, want %d", len(results), len(found))
+	}
+
+	for _, r := range results {
+		if !slices.Contains(valid, r.Level) {
+			t.Errorf("%s has invalid level %s", r.RuleID, r.Level)
+		}
+	}
+
+	// Results are sorted, and those without a file have no location
+	if len(results[0].Locations) > 0 {
+		t.Errorf("got locations for %q", results[0].Message.Text)
+	}
+
+	if results[1].Locations[0].Physical.Region.StartLine != 1 {
+		t.Errorf("got results out of order: %v", results)
+	}
+}
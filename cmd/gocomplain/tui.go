@@ -0,0 +1,366 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/mjwhitta/gocomplain"
+	hl "github.com/mjwhitta/hilighter"
+	"golang.org/x/term"
+)
+
+// Lines of source shown around the selected finding
+const tuiContext int = 3
+
+type tuiRow struct {
+	item int
+	text string
+}
+
+type tuiState struct {
+	cur    int
+	fd     int
+	items  []gocomplain.Finding
+	msg    string
+	old    *term.State
+	scroll int
+}
+
+// tui will let the user browse and triage the provided findings.
+func tui(found []gocomplain.Finding) error {
+	var e error
+	var key string
+	var s *tuiState = &tuiState{
+		fd:    int(os.Stdin.Fd()),
+		items: sortFindings(found),
+	}
+
+	if len(found) == 0 {
+		infof("No findings")
+		return nil
+	}
+
+	if !term.IsTerminal(s.fd) {
+		return fmt.Errorf("tui requires an interactive terminal")
+	}
+
+	if e = s.start(); e != nil {
+		return e
+	}
+	defer s.stop()
+
+	for len(s.items) > 0 {
+		s.draw()
+
+		if key, e = s.key(); e != nil {
+			return e
+		}
+
+		if !s.handle(key) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func tuiTrunc(str string, width int) string {
+	var runes []rune = []rune(str)
+
+	if len(runes) > width {
+		return string(runes[:max(width, 0)])
+	}
+
+	return str
+}
+
+func (s *tuiState) context(height int) []string {
+	var b []byte
+	var f gocomplain.Finding = s.items[s.cur]
+	var lines []string
+	var out []string
+	var start int
+
+	if (f.File == "") || (f.Line < 1) {
+		return nil
+	}
+
+	if b, _ = os.ReadFile(f.File); len(b) == 0 {
+		return nil
+	}
+
+	lines = strings.Split(string(b), "\n")
+	start = max(f.Line-1-tuiContext, 0)
+
+	for i := start; (i < len(lines)) && (len(out) < height); i++ {
+		if i == f.Line-1 {
+			out = append(
+				out,
+				hl.Sprintf("\x1b[1m%5d> %s\x1b[0m", i+1, lines[i]),
+			)
+		} else {
+			out = append(out, hl.Sprintf("%5d  %s", i+1, lines[i]))
+		}
+	}
+
+	return out
+}
+
+func (s *tuiState) draw() {
+	var ctx []string
+	var height int
+	var listHeight int
+	var rows []tuiRow = s.rows()
+	var sel int
+	var width int
+
+	width, height, _ = term.GetSize(s.fd)
+	width = max(width, 20)
+	height = max(height, 2*tuiContext+8)
+	listHeight = height - (2*tuiContext + 1) - 4
+
+	for i, row := range rows {
+		if row.item == s.cur {
+			sel = i
+		}
+	}
+
+	// Keep the selection, and its group headers, visible
+	if sel < s.scroll+2 {
+		s.scroll = max(sel-2, 0)
+	} else if sel >= s.scroll+listHeight {
+		s.scroll = sel - listHeight + 1
+	}
+
+	hl.Printf("\x1b[H\x1b[2J")
+	hl.Printf(
+		"%s\r\n",
+		tuiTrunc(
+			hl.Sprintf(
+				"gocomplain: finding %d of %d",
+				s.cur+1,
+				len(s.items),
+			),
+			width,
+		),
+	)
+
+	for i := s.scroll; i < s.scroll+listHeight; i++ {
+		if i >= len(rows) {
+			hl.Printf("\r\n")
+			continue
+		}
+
+		if i == sel {
+			hl.Printf(
+				"\x1b[7m%s\x1b[0m\r\n",
+				tuiTrunc(rows[i].text, width),
+			)
+		} else {
+			hl.Printf("%s\r\n", tuiTrunc(rows[i].text, width))
+		}
+	}
+
+	hl.Printf("%s\r\n", strings.Repeat("-", width))
+
+	ctx = s.context(2*tuiContext + 1)
+	for i := range 2*tuiContext + 1 {
+		if i < len(ctx) {
+			hl.Printf("%s\r\n", tuiTrunc(ctx[i], width))
+		} else {
+			hl.Printf("\r\n")
+		}
+	}
+
+	if s.msg == "" {
+		s.msg = "j/k: move, o: open in $EDITOR, " +
+			"b: baseline, i: ignore inline, q: quit"
+	}
+
+	hl.Printf("%s", tuiTrunc(s.msg, width))
+	s.msg = ""
+}
+
+func (s *tuiState) edit() error {
+	var cmd *exec.Cmd
+	var e error
+	var editor []string = strings.Fields(os.Getenv("EDITOR"))
+	var f gocomplain.Finding = s.items[s.cur]
+
+	if f.File == "" {
+		return fmt.Errorf("finding has no file")
+	}
+
+	// EDITOR may include arguments, like "code -w"
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+
+	s.stop()
+	defer func() {
+		if e := s.start(); e != nil {
+			s.msg = e.Error()
+		}
+	}()
+
+	cmd = exec.Command(
+		editor[0],
+		append(
+			editor[1:],
+			hl.Sprintf("+%d", max(f.Line, 1)),
+			f.File,
+		)...,
+	)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if e = cmd.Run(); e != nil {
+		return fmt.Errorf("failed to run %s: %w", editor[0], e)
+	}
+
+	return nil
+}
+
+// handle will act on the provided key, and return whether or not to
+// keep browsing.
+func (s *tuiState) handle(key string) bool {
+	var e error
+
+	switch key {
+	case "\x03", "q":
+		return false
+	case "\x1b[A", "k":
+		s.cur = max(s.cur-1, 0)
+	case "\x1b[B", "j":
+		s.cur = min(s.cur+1, len(s.items)-1)
+	case "\x1b[5~":
+		s.cur = max(s.cur-10, 0)
+	case "\x1b[6~":
+		s.cur = min(s.cur+10, len(s.items)-1)
+	case "g":
+		s.cur = 0
+	case "G":
+		s.cur = len(s.items) - 1
+	case "\r", "o":
+		if e = s.edit(); e != nil {
+			s.msg = e.Error()
+		}
+	case "b":
+		s.suppress(false)
+	case "i":
+		s.suppress(true)
+	}
+
+	return true
+}
+
+func (s *tuiState) key() (string, error) {
+	var b []byte = make([]byte, 8)
+	var e error
+	var n int
+
+	if n, e = os.Stdin.Read(b); e != nil {
+		return "", fmt.Errorf("failed to read key: %w", e)
+	}
+
+	return string(b[:n]), nil
+}
+
+func (s *tuiState) rows() []tuiRow {
+	var file string
+	var rows []tuiRow
+	var tool string
+
+	for i, f := range s.items {
+		if (i == 0) || (f.Tool != tool) {
+			tool = f.Tool
+			file = "\x00"
+			rows = append(rows, tuiRow{item: -1, text: "== " + tool})
+		}
+
+		if f.File != file {
+			file = f.File
+			rows = append(rows, tuiRow{item: -1, text: "  " + file})
+		}
+
+		rows = append(
+			rows,
+			tuiRow{
+				item: i,
+				text: hl.Sprintf(
+					"    %d:%d [%s] %s",
+					f.Line,
+					f.Col,
+					strings.Join(f.GOOS, ","),
+					f.Msg,
+				),
+			},
+		)
+	}
+
+	return rows
+}
+
+func (s *tuiState) start() error {
+	var e error
+
+	if s.old, e = term.MakeRaw(s.fd); e != nil {
+		return fmt.Errorf("failed to configure terminal: %w", e)
+	}
+
+	// Alternate screen and hidden cursor
+	hl.Printf("\x1b[?1049h\x1b[?25l")
+
+	return nil
+}
+
+func (s *tuiState) stop() {
+	hl.Printf("\x1b[?25h\x1b[?1049l")
+
+	if s.old != nil {
+		_ = term.Restore(s.fd, s.old)
+		s.old = nil
+	}
+}
+
+// suppress will record the selected finding in the baseline, or
+// ignore it inline, then remove it from the list.
+func (s *tuiState) suppress(inline bool) {
+	var e error
+	var f gocomplain.Finding = s.items[s.cur]
+
+	if inline {
+		e = gocomplain.IgnoreInline(f)
+	} else {
+		e = gocomplain.BaselineAdd(baselineFile, f)
+	}
+
+	if e != nil {
+		s.msg = e.Error()
+		return
+	}
+
+	s.items = slices.Delete(s.items, s.cur, s.cur+1)
+	s.cur = min(s.cur, max(len(s.items)-1, 0))
+
+	// Inline directives shift the remaining lines down
+	if inline {
+		for i := range s.items {
+			if s.items[i].File != f.File {
+				continue
+			}
+
+			if s.items[i].Line >= f.Line {
+				s.items[i].Line++
+			}
+		}
+
+		s.msg = "Ignored inline"
+	} else {
+		s.msg = "Added to " + baselineFile
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mjwhitta/gocomplain"
+	hl "github.com/mjwhitta/hilighter"
+	"github.com/mjwhitta/log"
+	"github.com/mjwhitta/pathname"
+)
+
+// How long to wait for a burst of file events to end
+const settle time.Duration = 250 * time.Millisecond
+
+// Tool output, keyed by tool name
+type toolOutput map[string][]string
+
+// Findings from the most recent pass, keyed by file or by target,
+// build tags, and package
+var (
+	fileFindings map[string]toolOutput = map[string]toolOutput{}
+	pkgFindings  map[string]toolOutput = map[string]toolOutput{}
+)
+
+// redraw will clear the screen and output the findings from the most
+// recent pass. Findings are collected again from scratch, so fixed
+// findings are dropped from any reports.
+func redraw() {
+	var e error
+	var out toolOutput
+	var tmp []string
+
+	findings = nil
+	merged = map[string]int{}
+
+	hl.Print("\x1b[H\x1b[2J")
+	infof("Watching for changes (Ctrl-C to quit)...")
+
+	for _, k := range slices.Sorted(maps.Keys(pkgFindings)) {
+		// Label findings with the target and tags they came from
+		tmp = strings.SplitN(k, ":", 3)
+		curGOOS = tmp[0]
+		curTags = tmp[1]
+		out = pkgFindings[k]
+
+		for _, tool := range slices.Sorted(maps.Keys(out)) {
+			output(tool, out[tool])
+		}
+	}
+
+	curGOOS = ""
+	curTags = ""
+
+	for _, k := range slices.Sorted(maps.Keys(fileFindings)) {
+		out = fileFindings[k]
+
+		for _, tool := range slices.Sorted(maps.Keys(out)) {
+			output(tool, out[tool])
+		}
+	}
+
+	if collecting() {
+		if e = report(findings); e != nil {
+			log.Warn(e.Error())
+		}
+	}
+}
+
+func watch() error {
+	var changed map[string][]string = map[string][]string{}
+	var other map[string][]string
+	var src map[string][]string
+	var tests map[string][]string
+
+	src, tests, other = gocomplain.FindSrcFiles(".", flags.prune...)
+
+	for _, m := range []map[string][]string{src, tests, other} {
+		for dir, files := range m {
+			changed[dir] = append(changed[dir], files...)
+		}
+	}
+
+	watchPass(changed)
+
+	return gocomplain.Watch(".", settle, watchPass, flags.prune...)
+}
+
+func watchFile(dir string, fn string) {
+	var isGo bool = strings.HasSuffix(fn, ".go")
+	var out toolOutput = toolOutput{}
+	var path string = filepath.Join(dir, fn)
+	var single map[string][]string = map[string][]string{dir: {fn}}
+
+	delete(fileFindings, path)
+
+	if ok, _ := pathname.DoesExist(path); !ok {
+		return
+	}
+
+	for _, tool := range tools {
+		switch tool {
+		case "gofmt":
+			if isGo {
+				out[tool] = gocomplain.GoFmt(single)
+			}
+		case "gofumpt":
+			if isGo {
+				out[tool] = gocomplain.GoFumpt(single)
+			}
+		case "line-length":
+			if isGo {
+				out[tool] = gocomplain.LineLength(
+					flags.length,
+					flags.exempt,
+					single,
+				)
+			}
+		case "spellcheck":
+			out[tool] = gocomplain.SpellCheck(
+				flags.ignore,
+				flags.skip,
+				single,
+			)
+		}
+	}
+
+	if len(out) > 0 {
+		fileFindings[path] = out
+	}
+}
+
+// watchOutput will return whether or not the provided file is a
+// report being written, which must not trigger another pass.
+func watchOutput(path string) bool {
+	var abs string
+	var tmp string
+
+	abs, _ = filepath.Abs(path)
+
+	for _, o := range outputs {
+		if o.path == "" {
+			continue
+		}
+
+		if tmp, _ = filepath.Abs(o.path); tmp == abs {
+			return true
+		}
+	}
+
+	return false
+}
+
+func watchPass(changed map[string][]string) {
+	var pkgs []string
+	var redo bool
+	var src map[string][]string
+	var tests map[string][]string
+
+	for dir, files := range changed {
+		for _, fn := range files {
+			if watchOutput(filepath.Join(dir, fn)) {
+				continue
+			}
+
+			redo = true
+			watchFile(dir, fn)
+
+			if !strings.HasSuffix(fn, ".go") {
+				continue
+			}
+
+			if !slices.Contains(pkgs, dir) {
+				pkgs = append(pkgs, dir)
+			}
+		}
+	}
+
+	if !redo {
+		return
+	}
+
+	src, tests, _ = gocomplain.FindSrcFiles(".", flags.prune...)
+
+	for _, goos := range oses {
+		setGOOS(goos)
+
+		for i := range len(tagSets) + 1 {
+			if i > 0 {
+				setTags(tagSets[i-1])
+			}
+
+			for _, dir := range pkgs {
+				// Tests are loaded with the package they test
+				watchPkg(dir, slices.Concat(src[dir], tests[dir]))
+			}
+		}
+
+		setTags("")
+	}
+
+	resetGOOS()
+	redraw()
+}
+
+// watchPkg will run the package tools against the provided package,
+// for the current target and build tags.
+func watchPkg(dir string, files []string) {
+	var analyzers []string
+	var key string = curGOOS + ":" + curTags + ":" + dir
+	var out toolOutput = toolOutput{}
+	var pkg map[string][]string = map[string][]string{dir: files}
+
+	delete(pkgFindings, key)
+
+	if len(files) == 0 {
+		return
+	}
+
+	for _, tool := range tools {
+		// Only some tools are affected by build tags
+		switch tool {
+		case "govet", "ineffassign", "staticcheck":
+		default:
+			if curTags != "" {
+				continue
+			}
+		}
+
+		switch tool {
+		case "gocyclo":
+			out[tool] = gocomplain.GoCyclo(
+				flags.over,
+				flags.cognitive,
+				pkg,
+			)
+		case "golint":
+			out[tool] = gocomplain.Lint(
+				flags.confidence,
+				cfg.Lint,
+				pkg,
+			)
+		case "govet", "ineffassign", "staticcheck":
+			analyzers = append(analyzers, tool)
+			out[tool] = nil
+		}
+	}
+
+	// Packages are loaded once for all analyzers
+	if len(analyzers) > 0 {
+		for _, f := range gocomplain.Analyze(analyzers, pkg) {
+			out[f.Tool] = append(out[f.Tool], f.String())
+		}
+	}
+
+	if len(out) > 0 {
+		pkgFindings[key] = out
+	}
+}
@@ -0,0 +1,390 @@
+package gocomplain
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+
+	hl "github.com/mjwhitta/hilighter"
+)
+
+// Complexity is the cyclomatic and cognitive complexity of a single
+// function. Cyclomatic complexity counts the independent paths
+// through a function (like gocyclo), while cognitive complexity
+// weighs control flow by how deeply it is nested, to estimate how
+// hard the function is to understand.
+type Complexity struct {
+	Cognitive  int
+	Cyclomatic int
+	Dir        string
+	Func       string
+	Pos        token.Position
+}
+
+// cognitive will calculate the cognitive complexity of a function.
+type cognitive struct {
+	name    string
+	nesting int
+	recv    string
+	score   int
+}
+
+// Complexities will return the complexity of every function in the
+// provided Go source files. Files which fail to parse are skipped,
+// and their errors are returned together.
+func Complexities(src ...map[string][]string) ([]Complexity, error) {
+	var e error
+	var errs []error
+	var out []Complexity
+	var tmp []Complexity
+
+	for i := range src {
+		for dir, files := range src[i] {
+			for _, fn := range files {
+				fn = filepath.Join(dir, fn)
+
+				if tmp, e = complexityFile(fn); e != nil {
+					errs = append(errs, e)
+					continue
+				}
+
+				out = append(out, tmp...)
+			}
+		}
+	}
+
+	return out, errors.Join(errs...)
+}
+
+// GoCyclo will analyze the provided Go source files for any functions
+// that are overly complex, either cyclomatically or cognitively. If
+// no files are provided, the current directory is checked.
+func GoCyclo(
+	over uint, cognitive uint, src ...map[string][]string,
+) []string {
+	var e error
+	var funcs []Complexity
+	var out []string
+	var tests map[string][]string
+	var tmp []Complexity
+
+	if len(src) == 0 {
+		src = make([]map[string][]string, 2)
+		src[0], tests, _ = FindSrcFiles(".")
+		src[1] = tests
+	}
+
+	for i := range src {
+		for dir, files := range src[i] {
+			for _, fn := range files {
+				fn = filepath.Join(dir, fn)
+
+				if tmp, e = complexityFile(fn); e != nil {
+					out = append(out, e.Error())
+					continue
+				}
+
+				funcs = append(funcs, tmp...)
+			}
+		}
+	}
+
+	for _, c := range funcs {
+		if c.Cyclomatic > int(over) {
+			out = append(
+				out,
+				hl.Sprintf(
+					"%s: cyclomatic complexity %d of func %s",
+					c.Pos,
+					c.Cyclomatic,
+					c.Func,
+				),
+			)
+		}
+
+		if c.Cognitive > int(cognitive) {
+			out = append(
+				out,
+				hl.Sprintf(
+					"%s: cognitive complexity %d of func %s",
+					c.Pos,
+					c.Cognitive,
+					c.Func,
+				),
+			)
+		}
+	}
+
+	return out
+}
+
+// complexityFile will return the complexity of every function in the
+// provided Go source file. Generated files are skipped.
+func complexityFile(fn string) ([]Complexity, error) {
+	var e error
+	var f *ast.File
+	var fset *token.FileSet = token.NewFileSet()
+	var out []Complexity
+
+	if f, e = parser.ParseFile(fset, fn, nil, 0); e != nil {
+		return nil, e
+	}
+
+	if ast.IsGenerated(f) {
+		return nil, nil
+	}
+
+	for _, decl := range f.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok && (fd.Body != nil) {
+			out = append(
+				out,
+				Complexity{
+					Cognitive:  complexityCognitive(fd),
+					Cyclomatic: complexityCyclomatic(fd),
+					Dir:        filepath.Dir(fn),
+					Func:       complexityName(f.Name.Name, fd),
+					Pos:        fset.Position(fd.Pos()),
+				},
+			)
+		}
+	}
+
+	return out, nil
+}
+
+// complexityCognitive will return the cognitive complexity of the
+// provided function. Each break in linear flow adds one, plus one
+// for each level it is nested. Sequences of like boolean operators
+// and recursive calls also add one.
+func complexityCognitive(fd *ast.FuncDecl) int {
+	var c *cognitive = &cognitive{name: fd.Name.Name}
+
+	if (fd.Recv != nil) && (len(fd.Recv.List) > 0) {
+		if names := fd.Recv.List[0].Names; len(names) > 0 {
+			c.recv = names[0].Name
+		}
+	}
+
+	c.walk(fd.Body)
+
+	return c.score
+}
+
+// complexityCyclomatic will return the cyclomatic complexity of the
+// provided function, counting the same branches as gocyclo.
+func complexityCyclomatic(fd *ast.FuncDecl) int {
+	var score int = 1
+
+	ast.Inspect(
+		fd,
+		func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.BinaryExpr:
+				switch n.Op {
+				case token.LAND, token.LOR:
+					score++
+				}
+			case *ast.CaseClause:
+				if n.List != nil {
+					score++
+				}
+			case *ast.CommClause:
+				if n.Comm != nil {
+					score++
+				}
+			case *ast.ForStmt, *ast.IfStmt, *ast.RangeStmt:
+				score++
+			}
+
+			return true
+		},
+	)
+
+	return score
+}
+
+// complexityName will return the name of the provided function, in
+// the same format as gocyclo (e.g. pkg.Func or pkg.(*T).Method).
+func complexityName(pkg string, fd *ast.FuncDecl) string {
+	if (fd.Recv == nil) || (len(fd.Recv.List) == 0) {
+		return pkg + "." + fd.Name.Name
+	}
+
+	return hl.Sprintf(
+		"%s.(%s).%s",
+		pkg,
+		complexityRecv(fd.Recv.List[0].Type),
+		fd.Name.Name,
+	)
+}
+
+// complexityRecv will return the receiver type of a method, without
+// any type parameters.
+func complexityRecv(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.IndexExpr:
+		return complexityRecv(expr.X)
+	case *ast.IndexListExpr:
+		return complexityRecv(expr.X)
+	case *ast.ParenExpr:
+		return complexityRecv(expr.X)
+	case *ast.StarExpr:
+		return "*" + complexityRecv(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+
+	return fmt.Sprint(expr)
+}
+
+// block will score a block of code nested one level deeper.
+func (c *cognitive) block(n ast.Node) {
+	c.nesting++
+	c.walk(n)
+	c.nesting--
+}
+
+// flatten will return the operands and boolean operators of the
+// provided expression, in order.
+func (c *cognitive) flatten(
+	expr ast.Expr, operands []ast.Expr, ops []token.Token,
+) ([]ast.Expr, []token.Token) {
+	var bin *ast.BinaryExpr
+	var ok bool
+
+	bin, ok = expr.(*ast.BinaryExpr)
+	if !ok || ((bin.Op != token.LAND) && (bin.Op != token.LOR)) {
+		return append(operands, expr), ops
+	}
+
+	operands, ops = c.flatten(bin.X, operands, ops)
+	ops = append(ops, bin.Op)
+
+	return c.flatten(bin.Y, operands, ops)
+}
+
+// ifStmt will score an if statement and its else branches. Else
+// branches add one, but nothing for nesting.
+func (c *cognitive) ifStmt(s *ast.IfStmt, elseIf bool) {
+	if elseIf {
+		c.score++
+	} else {
+		c.score += 1 + c.nesting
+	}
+
+	c.walk(s.Init)
+	c.walk(s.Cond)
+	c.block(s.Body)
+
+	switch s := s.Else.(type) {
+	case *ast.BlockStmt:
+		c.score++
+		c.block(s)
+	case *ast.IfStmt:
+		c.ifStmt(s, true)
+	}
+}
+
+// logical will score a sequence of boolean operators, adding one
+// each time the operator changes (e.g. a && b && c || d adds two).
+func (c *cognitive) logical(expr *ast.BinaryExpr) {
+	var last token.Token
+	var ops []token.Token
+	var operands []ast.Expr
+
+	operands, ops = c.flatten(expr, operands, ops)
+
+	for _, op := range ops {
+		if op != last {
+			c.score++
+			last = op
+		}
+	}
+
+	for _, operand := range operands {
+		c.walk(operand)
+	}
+}
+
+// recursive will return whether or not the provided call is to the
+// function being scored.
+func (c *cognitive) recursive(call *ast.CallExpr) bool {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return (c.recv == "") && (fn.Name == c.name)
+	case *ast.SelectorExpr:
+		if x, ok := fn.X.(*ast.Ident); ok && (c.recv != "") {
+			return (x.Name == c.recv) && (fn.Sel.Name == c.name)
+		}
+	}
+
+	return false
+}
+
+// visit will score the provided node, returning whether or not its
+// children still need to be visited.
+func (c *cognitive) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.BinaryExpr:
+		switch n.Op {
+		case token.LAND, token.LOR:
+			c.logical(n)
+			return false
+		}
+	case *ast.BranchStmt:
+		// Jumps to labels break linear flow
+		if (n.Label != nil) || (n.Tok == token.GOTO) {
+			c.score++
+		}
+	case *ast.CallExpr:
+		if c.recursive(n) {
+			c.score++
+		}
+	case *ast.ForStmt:
+		c.score += 1 + c.nesting
+		c.walk(n.Init)
+		c.walk(n.Cond)
+		c.walk(n.Post)
+		c.block(n.Body)
+		return false
+	case *ast.FuncLit:
+		c.block(n.Body)
+		return false
+	case *ast.IfStmt:
+		c.ifStmt(n, false)
+		return false
+	case *ast.RangeStmt:
+		c.score += 1 + c.nesting
+		c.walk(n.X)
+		c.block(n.Body)
+		return false
+	case *ast.SelectStmt:
+		c.score += 1 + c.nesting
+		c.block(n.Body)
+		return false
+	case *ast.SwitchStmt:
+		c.score += 1 + c.nesting
+		c.walk(n.Init)
+		c.walk(n.Tag)
+		c.block(n.Body)
+		return false
+	case *ast.TypeSwitchStmt:
+		c.score += 1 + c.nesting
+		c.walk(n.Init)
+		c.walk(n.Assign)
+		c.block(n.Body)
+		return false
+	}
+
+	return true
+}
+
+// walk will score the provided node and its children.
+func (c *cognitive) walk(n ast.Node) {
+	if n != nil {
+		ast.Inspect(n, c.visit)
+	}
+}
@@ -0,0 +1,221 @@
+package gocomplain
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestComplexity(t *testing.T) {
+	var tests []struct {
+		cognitive  int
+		cyclomatic int
+		name       string
+		src        string
+	} = []struct {
+		cognitive  int
+		cyclomatic int
+		name       string
+		src        string
+	}{
+		{
+			cognitive:  0,
+			cyclomatic: 1,
+			name:       "empty",
+			src:        "func a() {}",
+		},
+		{
+			cognitive:  3,
+			cyclomatic: 3,
+			name:       "else",
+			src: `func a(x int) int {
+				if x > 0 {
+					return 1
+				} else if x < 0 {
+					return -1
+				} else {
+					return 0
+				}
+			}`,
+		},
+		{
+			cognitive:  6,
+			cyclomatic: 4,
+			name:       "nested",
+			src: `func a(xs []int) {
+				for _, x := range xs {
+					if x > 0 {
+						for {
+							break
+						}
+					}
+				}
+			}`,
+		},
+		{
+			cognitive:  2,
+			cyclomatic: 4,
+			name:       "logical",
+			src: `func a(a, b, c, d bool) bool {
+				return a && b && c || d
+			}`,
+		},
+		{
+			cognitive:  1,
+			cyclomatic: 3,
+			name:       "switch",
+			src: `func a(x int) int {
+				switch x {
+				case 1:
+					return 1
+				case 2:
+					return 2
+				default:
+					return 0
+				}
+			}`,
+		},
+		{
+			cognitive:  3,
+			cyclomatic: 2,
+			name:       "recursive",
+			src: `func a(n int) int {
+				if n < 2 {
+					return n
+				}
+
+				return a(n-1) + a(n-2)
+			}`,
+		},
+		{
+			cognitive:  1,
+			cyclomatic: 1,
+			name:       "recursive method",
+			src:        "func (t *T) a(n int) int { return t.a(n) }",
+		},
+		{
+			cognitive:  2,
+			cyclomatic: 2,
+			name:       "closure",
+			src: `func a() {
+				var f func() = func() {
+					if true {
+					}
+				}
+
+				f()
+			}`,
+		},
+		{
+			cognitive:  4,
+			cyclomatic: 3,
+			name:       "label",
+			src: `func a() {
+			outer:
+				for {
+					for {
+						continue outer
+					}
+				}
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				var fd *ast.FuncDecl = complexityParse(t, test.src)
+				var n int
+
+				if n = complexityCognitive(fd); n != test.cognitive {
+					t.Errorf(
+						"got cognitive %d, want %d",
+						n,
+						test.cognitive,
+					)
+				}
+
+				n = complexityCyclomatic(fd)
+				if n != test.cyclomatic {
+					t.Errorf(
+						"got cyclomatic %d, want %d",
+						n,
+						test.cyclomatic,
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestComplexities(t *testing.T) {
+	var e error
+	var funcs []Complexity
+	var src map[string][]string = map[string][]string{
+		".": {"a.go", "b.go"},
+	}
+
+	t.Chdir(t.TempDir())
+	spellWrite(t, "a.go", "package a\n\nfunc a() {}\n")
+	spellWrite(t, "b.go", "package a\n\nfunc b() {\n")
+
+	// Files which fail to parse don't stop the others
+	funcs, e = Complexities(src)
+
+	if e == nil {
+		t.Error("parse error was not returned")
+	} else if !strings.Contains(e.Error(), "b.go") {
+		t.Errorf("got %s, want b.go", e)
+	}
+
+	if (len(funcs) != 1) || (funcs[0].Func != "a.a") {
+		t.Errorf("got %v, want a.a", funcs)
+	}
+}
+
+func TestComplexityName(t *testing.T) {
+	var tests map[string]string = map[string]string{
+		"func a() {}":              "p.a",
+		"func (T) a() {}":          "p.(T).a",
+		"func (t *T) a() {}":       "p.(*T).a",
+		"func (t *T[K, V]) a() {}": "p.(*T).a",
+	}
+
+	for src, expected := range tests {
+		t.Run(
+			src,
+			func(t *testing.T) {
+				var fd *ast.FuncDecl = complexityParse(t, src)
+				var name string = complexityName("p", fd)
+
+				if name != expected {
+					t.Errorf("got %s, want %s", name, expected)
+				}
+			},
+		)
+	}
+}
+
+// complexityParse will return the function declared in the provided
+// source.
+func complexityParse(t *testing.T, src string) *ast.FuncDecl {
+	var e error
+	var f *ast.File
+
+	t.Helper()
+
+	f, e = parser.ParseFile(
+		token.NewFileSet(),
+		"a.go",
+		"package p\n\n"+src+"\n",
+		0,
+	)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	return f.Decls[0].(*ast.FuncDecl)
+}
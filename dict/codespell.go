@@ -0,0 +1,88 @@
+//go:build ignore
+
+// Codespell will download the dictionaries codespell uses by default
+// (clear and rare) into the dict directory, for embedding. Run it
+// with "go generate" from the module root.
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Codespell release to download dictionaries from
+const codespellRef string = "v2.4.1"
+
+// Notice prepended to each dictionary, as required by its license
+const codespellNotice string = `# Misspellings from codespell (%s)
+# https://github.com/codespell-project/codespell
+#
+# Licensed under CC BY-SA 3.0, derived from Wikipedia's list of common
+# misspellings: https://creativecommons.org/licenses/by-sa/3.0/
+#
+# Generated by dict/codespell.go, do not edit.
+`
+
+// Codespell dictionaries, mapped to their embedded names
+var codespellDicts map[string]string = map[string]string{
+	"dictionary.txt":      "codespell.txt",
+	"dictionary_rare.txt": "codespell_rare.txt",
+}
+
+func main() {
+	var e error
+
+	for src, dst := range codespellDicts {
+		if e = download(src, filepath.Join("dict", dst)); e != nil {
+			fmt.Fprintln(os.Stderr, e.Error())
+			os.Exit(1)
+		}
+	}
+}
+
+// download will save the provided codespell dictionary, with the
+// license notice, to the provided file.
+func download(src string, dst string) error {
+	var b []byte
+	var e error
+	var res *http.Response
+	var url string = strings.Join(
+		[]string{
+			"https://raw.githubusercontent.com",
+			"codespell-project/codespell",
+			codespellRef,
+			"codespell_lib/data",
+			src,
+		},
+		"/",
+	)
+
+	if res, e = http.Get(url); e != nil {
+		return fmt.Errorf("failed to download %s: %w", src, e)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf(
+			"failed to download %s: %s",
+			src,
+			res.Status,
+		)
+	}
+
+	if b, e = io.ReadAll(res.Body); e != nil {
+		return fmt.Errorf("failed to download %s: %w", src, e)
+	}
+
+	b = append(fmt.Appendf(nil, codespellNotice, codespellRef), b...)
+
+	if e = os.WriteFile(dst, b, 0o644); e != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, e)
+	}
+
+	return nil
+}
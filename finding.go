@@ -0,0 +1,78 @@
+package gocomplain
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	hl "github.com/mjwhitta/hilighter"
+)
+
+// Finding is a single complaint from one of the underlying tools.
+// GOOS lists the targets (GOOS or GOOS/GOARCH) it appeared under and
+// Tags lists the build tag sets it only appeared under.
+type Finding struct {
+	Col  int      `json:"col,omitempty"`
+	File string   `json:"file,omitempty"`
+	GOOS []string `json:"goos,omitempty"`
+	Line int      `json:"line,omitempty"`
+	Msg  string   `json:"msg"`
+	Tags []string `json:"tags,omitempty"`
+	Tool string   `json:"tool"`
+}
+
+// ParseFinding will parse a line of output from the provided tool
+// into a Finding. Lines that don't reference a file position are
+// kept as a message only.
+func ParseFinding(tool string, ln string) Finding {
+	var f Finding = Finding{Msg: ln, Tool: tool}
+	var m []string
+
+	if m = posLine.FindStringSubmatch(ln); m != nil {
+		f.File = filepath.Clean(m[1])
+		f.Line, _ = strconv.Atoi(m[2])
+		f.Col, _ = strconv.Atoi(m[3])
+		f.Msg = strings.TrimSpace(m[4])
+	} else if strings.HasSuffix(ln, ".go") {
+		// gofmt and gofumpt only list files
+		f.File = filepath.Clean(ln)
+		f.Msg = "file was reformatted"
+	}
+
+	return f
+}
+
+// Correction will return the misspelled word and any suggested
+// replacements, if the Finding is a spelling complaint.
+func (f Finding) Correction() (string, []string) {
+	var fixes []string
+	var m []string
+
+	if m = typoFixMsg.FindStringSubmatch(f.Msg); m != nil {
+		for _, fix := range strings.Split(m[2], ",") {
+			if fix = strings.TrimSpace(fix); fix != "" {
+				fixes = append(fixes, fix)
+			}
+		}
+
+		return m[1], fixes
+	}
+
+	return "", nil
+}
+
+// String will return the Finding in the "file:line:col: msg" format
+// used by most of the underlying tools.
+func (f Finding) String() string {
+	if f.File == "" {
+		return f.Msg
+	}
+
+	return hl.Sprintf(
+		"%s:%d:%d: %s",
+		f.File,
+		max(f.Line, 1),
+		max(f.Col, 1),
+		f.Msg,
+	)
+}
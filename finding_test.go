@@ -0,0 +1,89 @@
+package gocomplain
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestCorrection(t *testing.T) {
+	var fixes []string
+	var typo string
+
+	typo, fixes = ParseFinding(
+		"spellcheck",
+		"a.go:1:4: mesage ==> message, massage",
+	).Correction()
+
+	if typo != "mesage" {
+		t.Errorf("got typo %s, want mesage", typo)
+	}
+
+	if !slices.Equal(fixes, []string{"message", "massage"}) {
+		t.Errorf("got fixes %v", fixes)
+	}
+}
+
+func TestParseFinding(t *testing.T) {
+	var tests []struct {
+		expected Finding
+		ln       string
+	} = []struct {
+		expected Finding
+		ln       string
+	}{
+		{
+			expected: Finding{
+				Col:  4,
+				File: "a.go",
+				Line: 3,
+				Msg:  "bad thing",
+				Tool: "govet",
+			},
+			ln: "a.go:3:4: bad thing",
+		},
+		{
+			expected: Finding{
+				File: filepath.Join("dir", "b.go"),
+				Line: 12,
+				Msg:  "no column",
+				Tool: "govet",
+			},
+			ln: "./dir/b.go:12: no column",
+		},
+		{
+			expected: Finding{
+				File: "c.go",
+				Msg:  "file was reformatted",
+				Tool: "govet",
+			},
+			ln: "c.go",
+		},
+		{
+			expected: Finding{Msg: "no position", Tool: "govet"},
+			ln:       "no position",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.ln,
+			func(t *testing.T) {
+				var f Finding = ParseFinding("govet", test.ln)
+
+				if !reflect.DeepEqual(f, test.expected) {
+					t.Errorf("got %+v, want %+v", f, test.expected)
+				}
+
+				// Findings round trip through String
+				if (f.File != "") && (f.Line > 0) {
+					f = ParseFinding("govet", f.String())
+					if f.Msg != test.expected.Msg {
+						t.Errorf("got %q after round trip", f.Msg)
+					}
+				}
+			},
+		)
+	}
+}
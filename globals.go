@@ -5,12 +5,21 @@ import (
 	"strings"
 )
 
+// Cache will enable reuse of previous findings for unchanged
+// packages.
+var Cache bool
+
 // CGO will turn on CGO support.
 var CGO bool
 
 // Debug will turn on debug log messages.
 var Debug bool
 
+// DisplayWidth will measure line length by display width, rather than
+// by runes, so East Asian wide characters and emoji count as two
+// columns and combining marks count as none.
+var DisplayWidth bool
+
 var (
 	alwaysIgnore *regexp.Regexp = regexp.MustCompile("" +
 		`\.git*|.*\.(` +
@@ -18,8 +27,19 @@ var (
 		`tar|tgz|xz|zip` +
 		`)`,
 	)
-	generated *regexp.Regexp = regexp.MustCompile(
-		`^//\sCode\sgenerated\s.*\sDO\sNOT\sEDIT\.$`,
+	cacheEnv []string = []string{
+		"CC",
+		"CGO_CFLAGS",
+		"CGO_ENABLED",
+		"CGO_LDFLAGS",
+		"CXX",
+		"GOARCH",
+		"GOFLAGS",
+		"GOOS",
+	}
+	goEscapes *regexp.Regexp = regexp.MustCompile("" +
+		`\\(?:[0-7]{3}|x[[:xdigit:]]{2}|` +
+		`u[[:xdigit:]]{4}|U[[:xdigit:]]{8}|.)`,
 	)
 	ignoredErr *regexp.Regexp = regexp.MustCompile(
 		strings.Join(
@@ -31,27 +51,55 @@ var (
 			"|",
 		),
 	)
+	posLine *regexp.Regexp = regexp.MustCompile(
+		`^(.+?):(\d+)(?::(\d+))?:?\s+(.*)$`,
+	)
+	spellSkip []string = []string{
+		".git*",
+		".gocomplain-*",
+		"*.db",
+		"*.der",
+		"*.dll",
+		"*.drawio",
+		"*.exe",
+		"*.gif",
+		"*.gz",
+		"*.jar",
+		"*.jpeg",
+		"*.jpg",
+		"*.pdf",
+		"*.pem",
+		"*.png",
+		"*.so",
+		"*.tar",
+		"*.tgz",
+		"*.xz",
+		"*.zip",
+		"go.mod",
+		"go.sum",
+	}
+	spellWords *regexp.Regexp = regexp.MustCompile(
+		`[\pL\pM]+(?:'[\pL\pM]+)*`,
+	)
 	structTags *regexp.Regexp = regexp.MustCompile("`.+:\".+\"`$")
+	typoFixMsg *regexp.Regexp = regexp.MustCompile(
+		`^(\S+)\s+==>\s+([^|]+)`,
+	)
+	urls *regexp.Regexp = regexp.MustCompile(
+		`\b[A-Za-z][-+.0-9A-Za-z]*://\S+`,
+	)
 )
 
-var pkgMgrs = [][]string{
-	// Alpine
-	{"apk", "sudo apk add py3-codespell"},
-	// Arch
-	{"yay", "yay -S codespell"},
-	{"pacman", "sudo pacman -S codespell"},
-	// Debian
-	{"apt-get", "sudo apt-get install codespell"},
-	{"apt", "sudo apt install codespell"},
-	// OpenSUSE
-	{"zypper", "sudo zypper in codespell"},
-	// RedHat
-	{"dnf", "sudo dnf install codespell"},
-	{"yum", "sudo yum install codespell"},
-}
+const ignoreDirective string = "//gocomplain:ignore"
+
+const wordsFile string = ".gocomplain-words"
 
 // Quiet can be used to disable information log messages.
 var Quiet bool
 
+// TabWidth is the number of columns a tab counts as when measuring
+// line length.
+var TabWidth uint = 4
+
 // Version is the package version.
 const Version string = "0.10.7"
@@ -1,16 +1,27 @@
 module github.com/mjwhitta/gocomplain
 
-go 1.23.0
+go 1.25.0
 
 require (
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/gordonklaus/ineffassign v0.2.0
 	github.com/mjwhitta/cli v1.12.9
 	github.com/mjwhitta/hilighter v1.11.12
 	github.com/mjwhitta/log v1.6.12
 	github.com/mjwhitta/pathname v1.2.9
 	github.com/mjwhitta/where v1.3.5
+	golang.org/x/term v0.14.0
+	golang.org/x/text v0.34.0
+	golang.org/x/tools v0.41.0
+	honnef.co/go/tools v0.7.0
 )
 
 require (
+	github.com/BurntSushi/toml v1.4.1-0.20240526193622-a339e1f7089c // indirect
 	github.com/mjwhitta/errors v1.0.5 // indirect
 	github.com/mjwhitta/safety v1.11.6 // indirect
+	golang.org/x/exp/typeparams v0.0.0-20231108232855-2478ac86f678 // indirect
+	golang.org/x/mod v0.32.0 // indirect
+	golang.org/x/sync v0.19.0 // indirect
+	golang.org/x/sys v0.40.0 // indirect
 )
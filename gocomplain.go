@@ -1,18 +1,18 @@
 package gocomplain
 
 import (
-	"bufio"
+	"bytes"
+	"fmt"
+	"go/build"
 	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
-	"runtime"
 	"slices"
-	"strconv"
 	"strings"
 
 	hl "github.com/mjwhitta/hilighter"
 	"github.com/mjwhitta/log"
-	"github.com/mjwhitta/where"
 )
 
 // FindSrcFiles will recursively traverse the provided directory and
@@ -71,150 +71,149 @@ func FindSrcFiles(
 	return src, tests, other
 }
 
-// GoCyclo will analyze the provided Go source files for any functions
-// that are overly complex.
-func GoCyclo(over uint) []string {
-	return run(
-		[]string{"gocyclo", "--over", strconv.Itoa(int(over)), "."},
-	)
-}
+// GoBuild will compile all packages, discarding any binaries, to
+// find errors that only occur when building (e.g. cgo or linking).
+func GoBuild(src ...map[string][]string) []string {
+	var e error
+	var out []string
+	var tmp string
 
-// GoFmt will format and simplify all Go source files.
-func GoFmt() []string {
-	return run([]string{"gofmt", "-l", "-s", "-w", "."})
+	if tmp, e = os.MkdirTemp("", "gocomplain-build-"); e != nil {
+		return []string{
+			hl.Sprintf("failed to create temp directory: %s", e),
+		}
+	}
+	defer os.RemoveAll(tmp)
+
+	// Trailing separator ensures binaries are written to tmp
+	tmp += string(filepath.Separator)
+
+	for _, ln := range runEach(
+		[]string{"go", "build", "-o", tmp},
+		"./...",
+		src...,
+	) {
+		// Skip package headers
+		if !strings.HasPrefix(ln, "# ") {
+			out = append(out, ln)
+		}
+	}
+
+	return out
 }
 
-// GoFumpt will format and optimize all Go source files.
-func GoFumpt() []string {
-	return run([]string{"gofumpt", "-e", "-l", "-w", "."})
+// GoFmt will format and simplify all Go source files.
+func GoFmt(src ...map[string][]string) []string {
+	return runEach([]string{"gofmt", "-l", "-s", "-w"}, ".", src...)
 }
 
-// GoLint will lint all packages.
-func GoLint(minConf float64) []string {
-	var c string = strconv.FormatFloat(minConf, 'f', -1, 64)
-	var cmd []string = []string{"golint"}
+// GoFmtSource will format and simplify the provided Go source
+// without writing anything to disk.
+func GoFmtSource(src []byte) ([]byte, error) {
+	var b []byte
+	var cmd *exec.Cmd = exec.Command("gofmt", "-s")
+	var e error
 
-	if minConf != 0.8 {
-		cmd = append(cmd, "-min_confidence", c)
+	if Debug {
+		log.Debugf("%s", strings.Join(cmd.Args, " "))
 	}
 
-	cmd = append(cmd, "./...")
+	cmd.Stdin = bytes.NewReader(src)
 
-	return run(cmd)
-}
+	if b, e = cmd.Output(); e != nil {
+		if e, ok := e.(*exec.ExitError); ok && (len(e.Stderr) > 0) {
+			return nil, fmt.Errorf("%s", bytes.TrimSpace(e.Stderr))
+		}
 
-// GoVet will vet all packages.
-func GoVet(src ...map[string][]string) []string {
-	var cmd []string
-	var out []string
+		return nil, fmt.Errorf("failed to run gofmt: %w", e)
+	}
 
-	if len(src) > 0 {
-		for i := range src {
-			for dir, files := range src[i] {
-				cmd = []string{"go", "vet"}
-				for _, file := range files {
-					cmd = append(cmd, filepath.Join(dir, file))
-				}
+	return b, nil
+}
 
-				out = append(out, run(cmd)...)
-			}
-		}
+// GoFumpt will format and optimize all Go source files.
+func GoFumpt(src ...map[string][]string) []string {
+	return runEach([]string{"gofumpt", "-e", "-l", "-w"}, ".", src...)
+}
 
-		return out
-	}
+// GoLint will lint the provided Go source files using the default
+// lint rules.
+//
+// Deprecated: Use Lint, which supports configuring rules.
+func GoLint(minConf float64, src ...map[string][]string) []string {
+	return Lint(minConf, nil, src...)
+}
 
-	return run([]string{"go", "vet", "./..."})
+// GoVet will vet all packages, in process.
+func GoVet(src ...map[string][]string) []string {
+	return analyzeLines("govet", src...)
 }
 
 // IneffAssign will analyze all packages for any inefficient variable
-// assignments.
+// assignments, in process.
 func IneffAssign(src ...map[string][]string) []string {
-	var cmd []string
-	var out []string
+	return analyzeLines("ineffassign", src...)
+}
 
-	if len(src) > 0 {
-		for i := range src {
-			for dir, files := range src[i] {
-				cmd = []string{"ineffassign"}
-				for _, file := range files {
-					cmd = append(cmd, filepath.Join(dir, file))
-				}
+// Misspell will look for spelling errors in provided Go source files.
+//
+// Deprecated: Use SpellCheck, which no longer needs misspell.
+func Misspell(ignore []string, src ...map[string][]string) []string {
+	return SpellCheck(ignore, nil, src...)
+}
 
-				out = append(out, run(cmd)...)
-			}
-		}
+// StaticCheck will perform static analysis on all packages, in
+// process.
+func StaticCheck(src ...map[string][]string) []string {
+	return analyzeLines("staticcheck", src...)
+}
 
-		return out
+// Targets will return the GOOS/GOARCH pairs supported by the
+// installed Go toolchain.
+func Targets() ([]string, error) {
+	var e error
+	var stdout string
+
+	stdout, e = execute([]string{"go", "tool", "dist", "list"})
+	if e != nil {
+		return nil, fmt.Errorf("failed to list targets: %w", e)
 	}
 
-	return run([]string{"ineffassign", "./..."})
+	return strings.Fields(stdout), nil
 }
 
-// LineLength will analyze the provided Go files for lines that are
-// longer than the provided threshold.
-func LineLength(threshold uint, src ...map[string][]string) []string {
+// Unchecked will return the provided Go files which are excluded by
+// their build constraints under every provided target (GOOS/GOARCH
+// pair) and build tag set (comma-separated, empty for none). These
+// files are never seen by the tools that load packages.
+func Unchecked(
+	targets []string, tagSets []string, src ...map[string][]string,
+) []string {
+	var ctxs []build.Context
 	var e error
-	var f *os.File
-	var line string
-	var lno int
+	var match bool
 	var out []string
-	var s *bufio.Scanner
+
+	for _, target := range targets {
+		for _, tags := range tagSets {
+			ctxs = append(ctxs, buildContext(target, tags))
+		}
+	}
 
 	for i := range src {
 		for dir, files := range src[i] {
 			for _, fn := range files {
+				match, e = matchAny(ctxs, dir, fn)
 				fn = filepath.Join(dir, fn)
 
-				// Open file
-				if f, e = os.Open(fn); e != nil {
-					out = append(
-						out,
-						hl.Sprintf("failed to read %s: %s", fn, e),
-					)
-					continue
-				}
-
-				// Read file
-				lno = 0
-				s = bufio.NewScanner(f)
-				for s.Scan() {
-					line = strings.ReplaceAll(s.Text(), "\t", "    ")
-					lno++
-
-					if generated.MatchString(line) {
-						break
-					}
-
-					if strings.HasPrefix(line, "//go:") {
-						continue
-					}
-
-					if structTags.MatchString(line) {
-						continue
-					}
-
-					if ll := len([]rune(line)); ll > int(threshold) {
-						out = append(
-							out,
-							hl.Sprintf(
-								"%s:%d (%d) %s",
-								fn,
-								lno,
-								ll,
-								line,
-							),
-						)
-					}
-				}
-
-				if e = s.Err(); e != nil {
+				if e != nil {
+					out = append(out, e.Error())
+				} else if !match {
 					out = append(
 						out,
-						hl.Sprintf("failed to read %s: %s", fn, e),
+						fn+":1: not compiled for any checked target",
 					)
 				}
-
-				f.Close()
 			}
 		}
 	}
@@ -222,123 +221,6 @@ func LineLength(threshold uint, src ...map[string][]string) []string {
 	return out
 }
 
-// Misspell will look for spelling errors in provided Go source files.
-func Misspell(ignore []string, src ...map[string][]string) []string {
-	var cmd []string = []string{"misspell"}
-	var out []string
-	var tmp []string
-
-	if len(ignore) > 0 {
-		cmd = append(cmd, "-i", strings.Join(ignore, ","))
-	}
-
-	if len(src) > 0 {
-		for i := range src {
-			for dir, files := range src[i] {
-				tmp = []string{}
-
-				for _, file := range files {
-					tmp = append(tmp, filepath.Join(dir, file))
-				}
-
-				out = append(out, run(append(cmd, tmp...))...)
-			}
-		}
-
-		return out
-	}
-
-	return run(append(cmd, "."))
-}
-
-// SpellCheck will run the appropriate tool for the current OS and
-// check for spelling errors in the provided Go source files.
-func SpellCheck(
-	ignore []string, skip []string, src ...map[string][]string,
-) []string {
-	var cmd []string
-
-	switch runtime.GOOS {
-	case "darwin", "linux":
-		if where.Is("codespell") == "" {
-			return []string{"codespell not found in PATH"}
-		}
-
-		cmd = []string{"codespell", "-d", "-f"}
-		if len(ignore) > 0 {
-			cmd = append(
-				cmd,
-				"-L",
-				strings.ToLower(strings.Join(ignore, ",")),
-			)
-		}
-
-		skip = append(
-			skip,
-			".git*",
-			"*.db",
-			"*.der",
-			"*.dll",
-			"*.exe",
-			"*.drawio",
-			"*.exe",
-			"*.gif",
-			"*.gz",
-			"*.jar",
-			"*.jpeg",
-			"*.jpg",
-			"*.pdf",
-			"*.pem",
-			"*.png",
-			"*.so",
-			"*.tar",
-			"*.tgz",
-			"*.xz",
-			"*.zip",
-			"go.mod",
-			"go.sum",
-		)
-		cmd = append(cmd, "-S", strings.Join(skip, ","))
-
-		return run(cmd)
-	// case "windows":
-	// TODO find spellcheck tool for windows (codespell?)
-	default:
-		return []string{
-			hl.Sprintf("unsupported OS: %s", runtime.GOOS),
-		}
-	}
-}
-
-// StaticCheck will perform static analysis on all packages.
-func StaticCheck(src ...map[string][]string) []string {
-	var cmd []string
-	var out []string
-
-	if len(src) > 0 {
-		for i := range src {
-			for dir, files := range src[i] {
-				cmd = []string{"staticcheck"}
-				for _, file := range files {
-					cmd = append(cmd, filepath.Join(dir, file))
-				}
-
-				out = append(out, run(cmd)...)
-			}
-		}
-
-		return out
-	}
-
-	return run(
-		[]string{
-			"staticcheck",
-			"--checks=all,-ST1000,-ST1023",
-			"./...",
-		},
-	)
-}
-
 // UpdateInstall will install the newest versions of the underlying
 // tools.
 func UpdateInstall() {
@@ -349,14 +231,8 @@ func UpdateInstall() {
 		"--ldflags=-s -w",
 		"--trimpath",
 	}
-	var found bool
 	var tools [][]string = [][]string{
-		{"gocyclo", "github.com/fzipp/gocyclo/cmd/gocyclo"},
 		{"gofumpt", "mvdan.cc/gofumpt"},
-		{"golint", "golang.org/x/lint/golint"},
-		{"ineffassign", "github.com/gordonklaus/ineffassign"},
-		{"misspell", "github.com/client9/misspell/cmd/misspell"},
-		{"staticcheck", "honnef.co/go/tools/cmd/staticcheck"},
 	}
 
 	info("Installing newest versions of each tool...")
@@ -364,34 +240,4 @@ func UpdateInstall() {
 		subInfof("%s...", tool[0])
 		run(append(cmd, tool[1]+"@latest"))
 	}
-
-	switch runtime.GOOS {
-	case "darwin":
-		if where.Is("codespell") == "" {
-			log.Warn("Please run \"brew install codespell\".")
-		}
-	case "linux":
-		if where.Is("codespell") != "" {
-			return
-		}
-
-		for _, pkgMgr := range pkgMgrs {
-			if where.Is(pkgMgr[0]) != "" {
-				found = true
-				log.Warnf("Please run \"%s\".", pkgMgr[1])
-				break
-			}
-		}
-
-		if !found {
-			log.Warn("Unknown package manager.")
-			log.Warn("Please install codespell.")
-		}
-	case "windows":
-		// TODO install spellcheck tool for windows
-		log.Warnf(
-			"spellcheck tool for %s not implemented",
-			runtime.GOOS,
-		)
-	}
 }
@@ -0,0 +1,558 @@
+package gocomplain
+
+import (
+	"bytes"
+	"cmp"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	hl "github.com/mjwhitta/hilighter"
+	"golang.org/x/text/width"
+)
+
+// lineWrap is a single-line list (call arguments, composite literal
+// elements, or function parameters/results) which could be wrapped.
+type lineWrap struct {
+	close token.Pos
+	elems [][2]token.Pos
+	open  token.Pos
+	sig   bool
+}
+
+// longLine is a line which exceeds the line length limit. Col is
+// the byte offset (+1) where the limit is crossed and offset is the
+// byte offset of the start of the line.
+type longLine struct {
+	col    int
+	length int
+	line   int
+	offset int
+}
+
+// LineLengthExemptions are the optional exemptions supported by
+// LineLength.
+var LineLengthExemptions []string = []string{
+	"imports",
+	"strings",
+	"urls",
+}
+
+// LineLength will analyze the provided Go files for lines that are
+// longer than the provided threshold, reporting the column where the
+// threshold is crossed. Width is measured per TabWidth and
+// DisplayWidth. Compiler directives and struct tags are always
+// exempt. Import paths, string literals, and URLs in comments are
+// exempt if listed in the provided exemptions. Generated files are
+// skipped.
+func LineLength(
+	threshold uint, exempt []string, src ...map[string][]string,
+) []string {
+	var b []byte
+	var e error
+	var long []longLine
+	var out []string
+
+	for i := range src {
+		for dir, files := range src[i] {
+			for _, fn := range files {
+				fn = filepath.Join(dir, fn)
+
+				if b, e = os.ReadFile(fn); e != nil {
+					out = append(
+						out,
+						hl.Sprintf("failed to read %s: %s", fn, e),
+					)
+					continue
+				}
+
+				if isGenerated(fn, b) {
+					continue
+				}
+
+				long = lineLengthFile(fn, b, threshold, exempt)
+				for _, ll := range long {
+					out = append(
+						out,
+						hl.Sprintf(
+							"%s:%d:%d: line length %d exceeds %d",
+							fn,
+							ll.line,
+							ll.col,
+							ll.length,
+							threshold,
+						),
+					)
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// LineLengthFix will wrap long function calls, composite literals,
+// and function signatures in the provided Go files, one element per
+// line, like this project's own code. Lines it can't safely wrap are
+// left for LineLength to report. It returns the files that were
+// rewritten.
+func LineLengthFix(
+	threshold uint, exempt []string, src ...map[string][]string,
+) []string {
+	var b []byte
+	var e error
+	var fixed []byte
+	var info os.FileInfo
+	var out []string
+
+	for i := range src {
+		for dir, files := range src[i] {
+			for _, fn := range files {
+				fn = filepath.Join(dir, fn)
+
+				if info, e = os.Stat(fn); e != nil {
+					out = append(
+						out,
+						hl.Sprintf("failed to read %s: %s", fn, e),
+					)
+					continue
+				}
+
+				if b, e = os.ReadFile(fn); e != nil {
+					out = append(
+						out,
+						hl.Sprintf("failed to read %s: %s", fn, e),
+					)
+					continue
+				}
+
+				if isGenerated(fn, b) {
+					continue
+				}
+
+				fixed = lineLengthWrap(fn, b, threshold, exempt)
+				if bytes.Equal(fixed, b) {
+					continue
+				}
+
+				e = os.WriteFile(fn, fixed, info.Mode().Perm())
+				if e != nil {
+					out = append(
+						out,
+						hl.Sprintf("failed to write %s: %s", fn, e),
+					)
+					continue
+				}
+
+				out = append(out, fn)
+			}
+		}
+	}
+
+	return out
+}
+
+// lineLengthComment will return the exempt spans within the
+// provided comment, which starts at the provided offset. Directives
+// are always exempt.
+func lineLengthComment(
+	lit string, start int, exempt []string,
+) [][2]int {
+	var spans [][2]int
+
+	if strings.HasPrefix(lit, "//go:") {
+		return [][2]int{{start, start + len(lit)}}
+	} else if !slices.Contains(exempt, "urls") {
+		return nil
+	}
+
+	for _, m := range urls.FindAllStringIndex(lit, -1) {
+		spans = append(spans, [2]int{start + m[0], start + m[1]})
+	}
+
+	return spans
+}
+
+// lineLengthFile will return the lines of the provided Go source
+// which are too long and not exempt.
+func lineLengthFile(
+	fn string, b []byte, threshold uint, exempt []string,
+) []longLine {
+	var col int
+	var length int
+	var offset int
+	var out []longLine
+	var spans [][2]int = lineLengthSpans(fn, b, exempt)
+	var text []byte
+
+	for i, line := range bytes.Split(b, []byte("\n")) {
+		text = bytes.TrimSuffix(line, []byte("\r"))
+		length, col = lineWidth(string(text), threshold)
+
+		if (col > 0) && !lineLengthExempt(spans, offset+col-1) {
+			out = append(
+				out,
+				longLine{
+					col:    col,
+					length: length,
+					line:   i + 1,
+					offset: offset,
+				},
+			)
+		}
+
+		offset += len(line) + 1
+	}
+
+	return out
+}
+
+// lineLengthExempt will return whether or not the provided offset is
+// within any of the provided exempt spans.
+func lineLengthExempt(spans [][2]int, offset int) bool {
+	for _, span := range spans {
+		if (offset >= span[0]) && (offset < span[1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lineLengthSpans will return the start and end offsets of the
+// tokens in the provided Go source which are exempt from the line
+// length limit. A line is only exempt if the limit is crossed within
+// one of these.
+func lineLengthSpans(fn string, b []byte, exempt []string) [][2]int {
+	var f *token.File
+	var imports bool
+	var lit string
+	var paren bool
+	var pos token.Pos
+	var s scanner.Scanner
+	var spans [][2]int
+	var start int
+	var tok token.Token
+
+	f = token.NewFileSet().AddFile(fn, -1, len(b))
+	s.Init(f, b, nil, scanner.ScanComments)
+
+	for {
+		if pos, tok, lit = s.Scan(); tok == token.EOF {
+			break
+		}
+
+		start = f.Offset(pos)
+
+		switch tok {
+		case token.COMMENT:
+			spans = append(
+				spans,
+				lineLengthComment(lit, start, exempt)...,
+			)
+		case token.IMPORT:
+			imports = true
+		case token.LPAREN:
+			paren = imports
+		case token.RPAREN:
+			imports = false
+			paren = false
+		case token.SEMICOLON:
+			imports = imports && paren
+		case token.STRING:
+			spans = append(
+				spans,
+				lineLengthString(b, lit, start, imports, exempt)...,
+			)
+		}
+	}
+
+	return spans
+}
+
+// lineLengthString will return the span of the provided string,
+// which starts at the provided offset, if it is exempt. Struct tags
+// are always exempt.
+func lineLengthString(
+	b []byte, lit string, start int, imports bool, exempt []string,
+) [][2]int {
+	var end int = start + len(lit)
+
+	// Raw strings drop carriage returns, so find the end
+	if strings.HasPrefix(lit, "`") {
+		end = bytes.IndexByte(b[start+1:], '`') + start + 2
+	}
+
+	if imports && slices.Contains(exempt, "imports") {
+		return [][2]int{{start, end}}
+	} else if structTags.MatchString(lit) {
+		return [][2]int{{start, end}}
+	} else if slices.Contains(exempt, "strings") {
+		return [][2]int{{start, end}}
+	}
+
+	return nil
+}
+
+// lineLengthWrap will repeatedly wrap the outermost single-line list
+// on each long line of the provided Go source, until there is nothing
+// left to wrap. If the source can't be parsed, or the result can't be
+// formatted, the last good source is returned.
+func lineLengthWrap(
+	fn string, b []byte, threshold uint, exempt []string,
+) []byte {
+	var e error
+	var f *ast.File
+	var fset *token.FileSet
+	var long []longLine
+	var tmp []byte
+	var wraps []lineWrap
+
+	for {
+		fset = token.NewFileSet()
+
+		f, e = parser.ParseFile(fset, fn, b, parser.ParseComments)
+		if e != nil {
+			return b
+		}
+
+		long = lineLengthFile(fn, b, threshold, exempt)
+		if wraps = lineWrapSelect(fset, f, long); len(wraps) == 0 {
+			return b
+		}
+
+		// Wrap from the end, so earlier offsets are still valid
+		tmp = b
+		for i := len(wraps) - 1; i >= 0; i-- {
+			tmp = lineWrapApply(tmp, fset, wraps[i], threshold)
+		}
+
+		if tmp, e = format.Source(tmp); e != nil {
+			return b
+		}
+
+		b = tmp
+	}
+}
+
+// lineWidth will return the width of the provided line, and the
+// column (byte offset + 1) of the first character past the provided
+// threshold, or 0 if it fits.
+func lineWidth(line string, threshold uint) (int, int) {
+	var col int
+	var length int
+
+	for i, r := range line {
+		length += runeWidth(r)
+
+		if (col == 0) && (length > int(threshold)) {
+			col = i + 1
+		}
+	}
+
+	return length, col
+}
+
+// lineWrapApply will wrap the provided list, one element per line.
+// Signatures are wrapped onto a single indented line, if it fits.
+func lineWrapApply(
+	b []byte, fset *token.FileSet, w lineWrap, threshold uint,
+) []byte {
+	var closeAt int = fset.Position(w.close).Offset
+	var col int
+	var elems []string
+	var from int
+	var indent []byte
+	var lineAt int
+	var openAt int = fset.Position(w.open).Offset
+	var sep string = ",\n"
+	var to int
+
+	lineAt = bytes.LastIndexByte(b[:openAt], '\n') + 1
+	indent = b[lineAt:openAt]
+	indent = indent[:len(indent)-len(bytes.TrimLeft(indent, " \t"))]
+
+	for _, elem := range w.elems {
+		from = fset.Position(elem[0]).Offset
+		to = fset.Position(elem[1]).Offset
+		elems = append(elems, string(b[from:to]))
+	}
+
+	if w.sig {
+		_, col = lineWidth(
+			string(indent)+"\t"+strings.Join(elems, ", ")+",",
+			threshold,
+		)
+		if col == 0 {
+			sep = ", "
+		}
+	}
+
+	return slices.Concat(
+		b[:openAt+1],
+		[]byte("\n"+strings.Join(elems, sep)+",\n"),
+		b[closeAt:],
+	)
+}
+
+// lineWrapCandidates will return every non-empty call, composite
+// literal, and function signature list in the provided file.
+func lineWrapCandidates(f *ast.File) []lineWrap {
+	var out []lineWrap
+
+	ast.Inspect(
+		f,
+		func(n ast.Node) bool {
+			var w lineWrap
+
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				w = lineWrap{close: n.Rparen, open: n.Lparen}
+
+				for _, arg := range n.Args {
+					w.elems = append(
+						w.elems,
+						[2]token.Pos{arg.Pos(), arg.End()},
+					)
+				}
+
+				if n.Ellipsis.IsValid() && (len(w.elems) > 0) {
+					w.elems[len(w.elems)-1][1] = n.Ellipsis + 3
+				}
+			case *ast.CompositeLit:
+				w = lineWrap{close: n.Rbrace, open: n.Lbrace}
+
+				for _, elt := range n.Elts {
+					w.elems = append(
+						w.elems,
+						[2]token.Pos{elt.Pos(), elt.End()},
+					)
+				}
+			case *ast.FuncType:
+				out = append(out, lineWrapFields(n.Params)...)
+				out = append(out, lineWrapFields(n.Results)...)
+			}
+
+			if len(w.elems) > 0 {
+				out = append(out, w)
+			}
+
+			return true
+		},
+	)
+
+	return out
+}
+
+// lineWrapCommented will return whether or not there are any comments
+// within the provided list, which wrapping could misplace.
+func lineWrapCommented(f *ast.File, w lineWrap) bool {
+	for _, cg := range f.Comments {
+		if (cg.Pos() > w.open) && (cg.End() <= w.close) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lineWrapFields will return the provided function parameters or
+// results as a list to wrap, if they are parenthesized.
+func lineWrapFields(fl *ast.FieldList) []lineWrap {
+	var w lineWrap
+
+	if (fl == nil) || !fl.Opening.IsValid() || (len(fl.List) == 0) {
+		return nil
+	}
+
+	w = lineWrap{close: fl.Closing, open: fl.Opening, sig: true}
+
+	for _, field := range fl.List {
+		w.elems = append(
+			w.elems,
+			[2]token.Pos{field.Pos(), field.End()},
+		)
+	}
+
+	return []lineWrap{w}
+}
+
+// lineWrapSelect will return the outermost single-line list on each
+// of the provided long lines, ordered by position. Lists which open
+// after the limit is crossed, or contain comments, are left alone.
+func lineWrapSelect(
+	fset *token.FileSet, f *ast.File, long []longLine,
+) []lineWrap {
+	var cross map[int]int = map[int]int{}
+	var found map[int]lineWrap = map[int]lineWrap{}
+	var line int
+	var ok bool
+	var out []lineWrap
+	var pos token.Position
+	var prev lineWrap
+
+	for _, ll := range long {
+		cross[ll.line] = ll.offset + ll.col - 1
+	}
+
+	for _, w := range lineWrapCandidates(f) {
+		pos = fset.Position(w.open)
+		line = pos.Line
+
+		// Only wrap lists that open before the limit is crossed
+		if fset.Position(w.close).Line != line {
+			continue
+		} else if _, ok = cross[line]; !ok {
+			continue
+		} else if pos.Offset >= cross[line] {
+			continue
+		} else if lineWrapCommented(f, w) {
+			continue
+		}
+
+		if prev, ok = found[line]; !ok || (w.open < prev.open) {
+			found[line] = w
+		}
+	}
+
+	for _, w := range found {
+		out = append(out, w)
+	}
+
+	slices.SortFunc(
+		out,
+		func(a lineWrap, b lineWrap) int {
+			return cmp.Compare(a.open, b.open)
+		},
+	)
+
+	return out
+}
+
+// runeWidth will return the number of columns the provided rune
+// occupies, per TabWidth and DisplayWidth.
+func runeWidth(r rune) int {
+	if r == '\t' {
+		return int(TabWidth)
+	} else if !DisplayWidth {
+		return 1
+	}
+
+	if unicode.In(r, unicode.Cf, unicode.Me, unicode.Mn) {
+		return 0
+	}
+
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianFullwidth, width.EastAsianWide:
+		return 2
+	}
+
+	return 1
+}
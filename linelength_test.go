@@ -0,0 +1,223 @@
+package gocomplain
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLineLength(t *testing.T) {
+	var wide string = "// " + strings.Repeat("日本語", 5)
+	var tests []struct {
+		display  bool
+		exempt   []string
+		expected []int
+		name     string
+		src      string
+	} = []struct {
+		display  bool
+		exempt   []string
+		expected []int
+		name     string
+		src      string
+	}{
+		{
+			expected: []int{4},
+			name:     "tabs",
+			src:      "func a() {\n\t\t\t\t\t\tprintln(1)\n}",
+		},
+		{
+			name: "directive",
+			src:  "//go:generate echo aaaaaaaaaaaaaaaaaaaaaaaaa",
+		},
+		{
+			name: "tags",
+			src: "type a struct {\n" +
+				"\tB int `json:\"bbbbbbbbbbbbbbbbbbbbbbb\"`\n" +
+				"}",
+		},
+		{
+			expected: []int{3},
+			name:     "imports",
+			src:      `import "example.com/aaaaaaaaaaaaaaaaaaaaaa"`,
+		},
+		{
+			exempt: []string{"imports"},
+			name:   "imports exempt",
+			src:    `import "example.com/aaaaaaaaaaaaaaaaaaaaaa"`,
+		},
+		{
+			expected: []int{3},
+			name:     "strings",
+			src:      `var a = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"`,
+		},
+		{
+			exempt: []string{"strings"},
+			name:   "strings exempt",
+			src:    `var a = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"`,
+		},
+		{
+			// Only the string is exempt, not what follows it
+			exempt:   []string{"strings"},
+			expected: []int{3},
+			name:     "strings exempt after",
+			src:      `var a = "aaaaaaaaaaaa" + "b" + "cccccccc"`,
+		},
+		{
+			expected: []int{3},
+			name:     "urls",
+			src:      "// See https://example.com/aaaaaaaaaaaaaaaa",
+		},
+		{
+			exempt: []string{"urls"},
+			name:   "urls exempt",
+			src:    "// See https://example.com/aaaaaaaaaaaaaaaa",
+		},
+		{
+			name: "runes",
+			src:  wide,
+		},
+		{
+			display:  true,
+			expected: []int{3},
+			name:     "display width",
+			src:      wide,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				var lines []int
+				var src string = "package a\n\n" + test.src + "\n"
+
+				t.Cleanup(func() { DisplayWidth = false })
+				DisplayWidth = test.display
+
+				for _, ll := range lineLengthFile(
+					"a.go",
+					[]byte(src),
+					30,
+					test.exempt,
+				) {
+					lines = append(lines, ll.line)
+				}
+
+				if !slices.Equal(lines, test.expected) {
+					t.Errorf(
+						"got %v, want %v",
+						lines,
+						test.expected,
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestLineLengthFix(t *testing.T) {
+	var b []byte
+	var e error
+	var out []string
+	var src map[string][]string = map[string][]string{
+		".": {"a.go"},
+	}
+	var tests []struct {
+		expected string
+		name     string
+		src      string
+	} = []struct {
+		expected string
+		name     string
+		src      string
+	}{
+		{
+			expected: "\tprintln(\n" +
+				"\t\t\"aaaaaaaaaaaa\",\n" +
+				"\t\t\"bbbbbbbbbbbb\",\n" +
+				"\t)\n",
+			name: "call",
+			src: "func a() {\n" +
+				"\tprintln(\"aaaaaaaaaaaa\", \"bbbbbbbbbbbb\")\n" +
+				"}\n",
+		},
+		{
+			expected: "var a []string = []string{\n" +
+				"\t\"aaaaaaaaaa\",\n" +
+				"\t\"bbbbbbbbbb\",\n" +
+				"}\n",
+			name: "literal",
+			src: "var a []string = " +
+				"[]string{\"aaaaaaaaaa\", \"bbbbbbbbbb\"}\n",
+		},
+		{
+			expected: "func a(\n" +
+				"\taaaa string, bbbb string,\n" +
+				") (string, error) {\n",
+			name: "signature",
+			src: "func a(aaaa string, bbbb string) " +
+				"(string, error) {\n" +
+				"\treturn aaaa, nil\n" +
+				"}\n",
+		},
+		{
+			expected: "func a(\n" +
+				"\taaaaaaaaaa string,\n" +
+				"\tbbbbbbbbbb string,\n" +
+				") {\n",
+			name: "signature long",
+			src: "func a(aaaaaaaaaa string, bbbbbbbbbb string) {\n" +
+				"}\n",
+		},
+		{
+			// Comments within the list aren't moved
+			expected: "println(\"aaaaaaaaaa\" /* a */, " +
+				"\"bbbbbbbbbb\")",
+			name: "commented",
+			src: "func a() {\n\t" +
+				"println(\"aaaaaaaaaa\" /* a */, \"bbbbbbbbbb\")" +
+				"\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				var orig string = "package a\n\n" + test.src
+
+				t.Chdir(t.TempDir())
+				spellWrite(t, "a.go", orig)
+
+				out = LineLengthFix(40, nil, src)
+
+				if b, e = os.ReadFile("a.go"); e != nil {
+					t.Fatal(e)
+				}
+
+				if !strings.Contains(string(b), test.expected) {
+					t.Errorf("got %q, want %q", b, test.expected)
+				}
+
+				_, e = parser.ParseFile(
+					token.NewFileSet(),
+					"a.go",
+					b,
+					0,
+				)
+				if e != nil {
+					t.Errorf("wrapped source is invalid: %s", e)
+				}
+
+				// Only rewritten files are returned
+				if (len(out) > 0) != (string(b) != orig) {
+					t.Errorf("got %q", out)
+				}
+			},
+		)
+	}
+}
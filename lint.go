@@ -0,0 +1,272 @@
+package gocomplain
+
+import (
+	"cmp"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	hl "github.com/mjwhitta/hilighter"
+)
+
+// LintRule will configure a single lint rule, using the same fields
+// as revive. Rules are enabled by default, unless they are opt-in,
+// in which case listing them enables them.
+type LintRule struct {
+	Arguments []any `json:"arguments,omitempty"`
+	Disabled  bool  `json:"disabled,omitempty"`
+}
+
+// lintFile is a parsed Go source file.
+type lintFile struct {
+	f    *ast.File
+	fn   string
+	test bool
+}
+
+// lintPkg is the files of a single package, and the problems found
+// in them so far.
+type lintPkg struct {
+	files    []*lintFile
+	fset     *token.FileSet
+	minConf  float64
+	name     string
+	problems []lintProblem
+}
+
+// lintProblem is a single complaint from a lint rule.
+type lintProblem struct {
+	msg string
+	pos token.Position
+}
+
+// lintRule is a lint rule implementation. Rules which are opt-in
+// only run when configured.
+type lintRule struct {
+	optIn bool
+	run   func(p *lintPkg, rule string, args []any)
+}
+
+// The supported lint rules, named after their revive equivalents
+var lintRules map[string]lintRule = map[string]lintRule{
+	"argument-limit":        {optIn: true, run: lintArgumentLimit},
+	"blank-imports":         {run: lintBlankImports},
+	"context-as-argument":   {run: lintContextAsArgument},
+	"dot-imports":           {run: lintDotImports},
+	"error-naming":          {run: lintErrorNaming},
+	"error-return":          {run: lintErrorReturn},
+	"error-strings":         {run: lintErrorStrings},
+	"errorf":                {run: lintErrorf},
+	"exported":              {run: lintExported},
+	"function-result-limit": {optIn: true, run: lintResultLimit},
+	"increment-decrement":   {run: lintIncDec},
+	"indent-error-flow":     {run: lintIndentErrorFlow},
+	"package-comments":      {run: lintPackageComments},
+	"range":                 {run: lintRange},
+	"receiver-naming":       {run: lintReceiverNaming},
+	"unexported-return":     {run: lintUnexportedReturn},
+	"var-naming":            {run: lintVarNaming},
+}
+
+// Lint will check the provided Go source files against the enabled
+// lint rules, skipping problems below the provided minimum
+// confidence. Rules are configured like revive, by name. If no files
+// are provided, the current directory is checked.
+func Lint(
+	minConf float64,
+	rules map[string]LintRule,
+	src ...map[string][]string,
+) []string {
+	var cfg LintRule
+	var e error
+	var enabled []string
+	var f *ast.File
+	var fset *token.FileSet = token.NewFileSet()
+	var key string
+	var keys []string
+	var ok bool
+	var out []string
+	var pkgs map[string]*lintPkg = map[string]*lintPkg{}
+	var tests map[string][]string
+
+	if len(src) == 0 {
+		src = make([]map[string][]string, 2)
+		src[0], tests, _ = FindSrcFiles(".")
+		src[1] = tests
+	}
+
+	for i := range src {
+		for dir, files := range src[i] {
+			for _, fn := range files {
+				fn = filepath.Join(dir, fn)
+
+				f, e = parser.ParseFile(
+					fset,
+					fn,
+					nil,
+					parser.ParseComments,
+				)
+				if e != nil {
+					out = append(out, e.Error())
+					continue
+				}
+
+				if ast.IsGenerated(f) {
+					continue
+				}
+
+				// External test packages are linted separately
+				key = dir + ":" + f.Name.Name
+				if pkgs[key] == nil {
+					keys = append(keys, key)
+					pkgs[key] = &lintPkg{
+						fset:    fset,
+						minConf: minConf,
+						name:    f.Name.Name,
+					}
+				}
+
+				pkgs[key].files = append(
+					pkgs[key].files,
+					&lintFile{
+						f:    f,
+						fn:   fn,
+						test: strings.HasSuffix(fn, "_test.go"),
+					},
+				)
+			}
+		}
+	}
+
+	for name, rule := range lintRules {
+		// Opt-in rules are enabled by configuring them
+		if cfg, ok = rules[name]; ok && cfg.Disabled {
+			continue
+		} else if !ok && rule.optIn {
+			continue
+		}
+
+		enabled = append(enabled, name)
+	}
+
+	slices.Sort(enabled)
+	slices.Sort(keys)
+
+	for _, key = range keys {
+		slices.SortFunc(
+			pkgs[key].files,
+			func(a *lintFile, b *lintFile) int {
+				return cmp.Compare(a.fn, b.fn)
+			},
+		)
+
+		for _, name := range enabled {
+			cfg = rules[name]
+			lintRules[name].run(pkgs[key], name, cfg.Arguments)
+		}
+
+		out = append(out, pkgs[key].output()...)
+	}
+
+	return out
+}
+
+// LintRules will return the names of the supported lint rules.
+func LintRules() []string {
+	var out []string
+
+	for name := range lintRules {
+		out = append(out, name)
+	}
+
+	slices.Sort(out)
+
+	return out
+}
+
+// lintArgInt will return the provided argument as an int, or the
+// provided default if it is missing or not a number.
+func lintArgInt(args []any, i int, def int) int {
+	if i >= len(args) {
+		return def
+	}
+
+	switch arg := args[i].(type) {
+	case float64:
+		return int(arg)
+	case int:
+		return arg
+	}
+
+	return def
+}
+
+// lintArgStrings will return the provided argument as a list of
+// strings. A single string is treated as a list of one.
+func lintArgStrings(args []any, i int) []string {
+	var out []string
+
+	if i >= len(args) {
+		return nil
+	}
+
+	switch arg := args[i].(type) {
+	case []any:
+		for _, str := range arg {
+			if str, ok := str.(string); ok {
+				out = append(out, str)
+			}
+		}
+	case []string:
+		out = append(out, arg...)
+	case string:
+		out = append(out, arg)
+	}
+
+	return out
+}
+
+// output will return the problems found in the package, ordered by
+// position, in the "file:line:col: msg" format.
+func (p *lintPkg) output() []string {
+	var out []string
+
+	slices.SortStableFunc(
+		p.problems,
+		func(a lintProblem, b lintProblem) int {
+			return cmp.Or(
+				cmp.Compare(a.pos.Filename, b.pos.Filename),
+				cmp.Compare(a.pos.Line, b.pos.Line),
+				cmp.Compare(a.pos.Column, b.pos.Column),
+			)
+		},
+	)
+
+	for _, prob := range p.problems {
+		out = append(out, hl.Sprintf("%s: %s", prob.pos, prob.msg))
+	}
+
+	return out
+}
+
+// report will record a problem at the provided node, if the provided
+// confidence meets the minimum. The rule name is appended to the
+// message, like the staticcheck check names.
+func (p *lintPkg) report(
+	rule string, conf float64, n ast.Node, msg string, args ...any,
+) {
+	if conf < p.minConf {
+		return
+	}
+
+	p.problems = append(
+		p.problems,
+		lintProblem{
+			msg: hl.Sprintf(msg, args...) + " (" + rule + ")",
+			pos: p.fset.Position(n.Pos()),
+		},
+	)
+}
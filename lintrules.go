@@ -0,0 +1,1344 @@
+package gocomplain
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Predeclared types, which are never unexported types
+var builtinTypes []string = []string{
+	"any",
+	"bool",
+	"byte",
+	"comparable",
+	"complex128",
+	"complex64",
+	"error",
+	"float32",
+	"float64",
+	"int",
+	"int16",
+	"int32",
+	"int64",
+	"int8",
+	"rune",
+	"string",
+	"uint",
+	"uint16",
+	"uint32",
+	"uint64",
+	"uint8",
+	"uintptr",
+}
+
+// Initialisms which should have consistent case, same as golint
+var commonInitialisms []string = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML",
+	"HTTP", "HTTPS", "ID", "IP", "JSON", "LHS", "QPS", "RAM", "RHS",
+	"RPC", "SLA", "SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP",
+	"UI", "UID", "UUID", "URI", "URL", "UTF8", "VM", "XML", "XMPP",
+	"XSRF", "XSS",
+}
+
+// lintArgumentLimit will complain about functions with more than the
+// configured number of parameters (default: 8).
+func lintArgumentLimit(p *lintPkg, rule string, args []any) {
+	var limit int = lintArgInt(args, 0, 8)
+
+	lintFuncTypes(
+		p,
+		func(ft *ast.FuncType) {
+			if n := lintCount(ft.Params); n > limit {
+				p.report(
+					rule,
+					1,
+					ft,
+					"maximum number of arguments per function"+
+						" exceeded; max %d but got %d",
+					limit,
+					n,
+				)
+			}
+		},
+	)
+}
+
+// lintBlankImports will complain about blank imports without a
+// comment, outside of main and test packages.
+func lintBlankImports(p *lintPkg, rule string, args []any) {
+	if p.name == "main" {
+		return
+	}
+
+	for _, lf := range p.files {
+		if lf.test {
+			continue
+		}
+
+		for _, imp := range lf.f.Imports {
+			if (imp.Name == nil) || (imp.Name.Name != "_") {
+				continue
+			}
+
+			if (imp.Doc == nil) && (imp.Comment == nil) {
+				p.report(
+					rule,
+					1,
+					imp,
+					"a blank import should be only in a main or test"+
+						" package, or have a comment justifying it",
+				)
+			}
+		}
+	}
+}
+
+// lintContextAsArgument will complain about functions which take a
+// context.Context, but not as the first parameter.
+func lintContextAsArgument(p *lintPkg, rule string, args []any) {
+	lintFuncTypes(
+		p,
+		func(ft *ast.FuncType) {
+			var leading bool = true
+
+			for _, field := range ft.Params.List {
+				if lintIsSelector(field.Type, "context", "Context") {
+					if !leading {
+						p.report(
+							rule,
+							0.9,
+							field,
+							"context.Context should be the first"+
+								" parameter of a function",
+						)
+						return
+					}
+				} else {
+					leading = false
+				}
+			}
+		},
+	)
+}
+
+// lintCount will return the number of fields in the provided list,
+// counting each name separately.
+func lintCount(fields *ast.FieldList) int {
+	var n int
+
+	if fields == nil {
+		return 0
+	}
+
+	for _, field := range fields.List {
+		n += max(len(field.Names), 1)
+	}
+
+	return n
+}
+
+// lintDotImports will complain about dot imports outside of tests.
+func lintDotImports(p *lintPkg, rule string, args []any) {
+	for _, lf := range p.files {
+		if lf.test {
+			continue
+		}
+
+		for _, imp := range lf.f.Imports {
+			if (imp.Name != nil) && (imp.Name.Name == ".") {
+				p.report(rule, 1, imp, "should not use dot imports")
+			}
+		}
+	}
+}
+
+// lintErrorf will complain about errors.New(fmt.Sprintf(...)).
+func lintErrorf(p *lintPkg, rule string, args []any) {
+	lintInspect(
+		p,
+		func(lf *lintFile, n ast.Node) {
+			var call *ast.CallExpr
+			var inner *ast.CallExpr
+			var ok bool
+
+			call, ok = n.(*ast.CallExpr)
+			if !ok || (len(call.Args) != 1) {
+				return
+			}
+
+			if !lintIsSelector(call.Fun, "errors", "New") {
+				return
+			}
+
+			inner, ok = call.Args[0].(*ast.CallExpr)
+			if ok && lintIsSelector(inner.Fun, "fmt", "Sprintf") {
+				p.report(
+					rule,
+					1,
+					call,
+					"should replace errors.New(fmt.Sprintf(...))"+
+						" with fmt.Errorf(...)",
+				)
+			}
+		},
+	)
+}
+
+// lintErrorNaming will complain about package-level error variables
+// not named errFoo or ErrFoo.
+func lintErrorNaming(p *lintPkg, rule string, args []any) {
+	var gd *ast.GenDecl
+	var ok bool
+	var prefix string
+	var vs *ast.ValueSpec
+
+	for _, lf := range p.files {
+		for _, decl := range lf.f.Decls {
+			gd, ok = decl.(*ast.GenDecl)
+			if !ok || (gd.Tok != token.VAR) {
+				continue
+			}
+
+			for _, spec := range gd.Specs {
+				vs = spec.(*ast.ValueSpec)
+
+				for i, id := range vs.Names {
+					if (i >= len(vs.Values)) || (id.Name == "_") {
+						continue
+					}
+
+					if !lintIsErrorCall(vs.Values[i]) {
+						continue
+					}
+
+					prefix = "err"
+					if id.IsExported() {
+						prefix = "Err"
+					}
+
+					if !strings.HasPrefix(id.Name, prefix) {
+						p.report(
+							rule,
+							0.9,
+							id,
+							"error var %s should have name of the"+
+								" form %sFoo",
+							id.Name,
+							prefix,
+						)
+					}
+				}
+			}
+		}
+	}
+}
+
+// lintErrorReturn will complain about functions which return an
+// error, but not as the last result.
+func lintErrorReturn(p *lintPkg, rule string, args []any) {
+	lintFuncTypes(
+		p,
+		func(ft *ast.FuncType) {
+			var results []ast.Expr
+
+			if ft.Results == nil {
+				return
+			}
+
+			for _, field := range ft.Results.List {
+				for range max(len(field.Names), 1) {
+					results = append(results, field.Type)
+				}
+			}
+
+			if len(results) < 2 {
+				return
+			}
+
+			if lintIsIdent(results[len(results)-1], "error") {
+				return
+			}
+
+			for _, res := range results[:len(results)-1] {
+				if lintIsIdent(res, "error") {
+					p.report(
+						rule,
+						0.9,
+						res,
+						"error should be the last type when"+
+							" returning multiple items",
+					)
+					return
+				}
+			}
+		},
+	)
+}
+
+// lintErrorString will return the confidence that the provided error
+// string is wrong, or 0 if it is fine. Proper nouns and exported
+// identifiers may be capitalized, so confidence is lower for those.
+func lintErrorString(str string) float64 {
+	var first rune
+	var last rune
+	var n int
+	var second rune
+
+	first, n = utf8.DecodeRuneInString(str)
+	last, _ = utf8.DecodeLastRuneInString(str)
+
+	switch last {
+	case '.', ':', '!', '\n':
+		return 0.8
+	}
+
+	if !unicode.IsUpper(first) {
+		return 0
+	}
+
+	// Allow initialisms
+	if second, _ = utf8.DecodeRuneInString(str[n:]); n == len(str) {
+		return 0.6
+	} else if !unicode.IsUpper(second) {
+		return 0.6
+	}
+
+	return 0
+}
+
+// lintErrorStrings will complain about error strings which are
+// capitalized or end with punctuation.
+func lintErrorStrings(p *lintPkg, rule string, args []any) {
+	lintInspect(
+		p,
+		func(lf *lintFile, n ast.Node) {
+			var call *ast.CallExpr
+			var conf float64
+			var lit *ast.BasicLit
+			var ok bool
+			var str string
+
+			call, ok = n.(*ast.CallExpr)
+			if !ok || (len(call.Args) == 0) {
+				return
+			}
+
+			if !lintIsSelector(call.Fun, "errors", "New") &&
+				!lintIsSelector(call.Fun, "fmt", "Errorf") {
+				return
+			}
+
+			lit, ok = call.Args[0].(*ast.BasicLit)
+			if !ok || (lit.Kind != token.STRING) {
+				return
+			}
+
+			if str, _ = strconv.Unquote(lit.Value); str == "" {
+				return
+			}
+
+			if conf = lintErrorString(str); conf > 0 {
+				p.report(
+					rule,
+					conf,
+					lit,
+					"error strings should not be capitalized or end"+
+						" with punctuation or a newline",
+				)
+			}
+		},
+	)
+}
+
+// lintExported will complain about exported identifiers without a
+// doc comment, or with one that doesn't start with their name. It
+// also complains about names that stutter (e.g. pkg.PkgFoo). The
+// arguments "checkPrivateReceivers" and "disableStutteringCheck" are
+// supported, same as revive.
+func lintExported(p *lintPkg, rule string, args []any) {
+	var private bool
+	var stutter bool = true
+
+	for _, arg := range args {
+		if str, ok := arg.(string); ok {
+			switch strings.ToLower(str) {
+			case "checkprivatereceivers":
+				private = true
+			case "disablestutteringcheck":
+				stutter = false
+			}
+		}
+	}
+
+	if p.name == "main" {
+		return
+	}
+
+	for _, lf := range p.files {
+		if lf.test {
+			continue
+		}
+
+		for _, decl := range lf.f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				lintExportedFunc(p, rule, decl, private, stutter)
+			case *ast.GenDecl:
+				lintExportedGen(p, rule, decl, stutter)
+			}
+		}
+	}
+}
+
+// lintExportedDoc will complain if the provided doc comment is
+// missing or doesn't start with the provided name.
+func lintExportedDoc(
+	p *lintPkg,
+	rule string,
+	n ast.Node,
+	doc *ast.CommentGroup,
+	kind string,
+	name string,
+	missing string,
+) {
+	var text string
+
+	if doc == nil {
+		p.report(rule, 1, n, missing, kind, name)
+		return
+	}
+
+	text = doc.Text()
+	name = name[strings.LastIndex(name, ".")+1:]
+
+	// Types may start with an article
+	if kind == "type" {
+		for _, article := range []string{"A ", "An ", "The "} {
+			text = strings.TrimPrefix(text, article)
+		}
+	}
+
+	if !strings.HasPrefix(text, name+" ") {
+		p.report(
+			rule,
+			1,
+			doc,
+			"comment on exported %s %s should be of the form \"%s"+
+				" ...\"",
+			kind,
+			name,
+			name,
+		)
+	}
+}
+
+// lintExportedFunc will check the provided exported function or
+// method.
+func lintExportedFunc(
+	p *lintPkg,
+	rule string,
+	fd *ast.FuncDecl,
+	private bool,
+	stutter bool,
+) {
+	var kind string = "function"
+	var name string = fd.Name.Name
+	var recv string
+
+	if !fd.Name.IsExported() {
+		return
+	}
+
+	if (fd.Recv != nil) && (len(fd.Recv.List) > 0) {
+		kind = "method"
+		recv = lintRecvType(fd.Recv.List[0].Type)
+
+		if !ast.IsExported(recv) && !private {
+			return
+		}
+
+		name = recv + "." + name
+	} else if stutter {
+		lintStutter(p, rule, fd.Name, "func")
+	}
+
+	lintExportedDoc(
+		p,
+		rule,
+		fd,
+		fd.Doc,
+		kind,
+		name,
+		"exported %s %s should have comment or be unexported",
+	)
+}
+
+// lintExportedGen will check the provided exported types, constants,
+// and variables.
+func lintExportedGen(
+	p *lintPkg, rule string, gd *ast.GenDecl, stutter bool,
+) {
+	var kind string
+	var missing string
+
+	switch gd.Tok {
+	case token.CONST:
+		kind = "const"
+	case token.TYPE:
+		kind = "type"
+	case token.VAR:
+		kind = "var"
+	default:
+		return
+	}
+
+	missing = "exported %s %s should have comment or be unexported"
+	if gd.Lparen.IsValid() {
+		missing = "exported %s %s should have comment (or a comment" +
+			" on this block) or be unexported"
+	}
+
+	for _, spec := range gd.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			lintExportedType(p, rule, gd, spec, missing, stutter)
+		case *ast.ValueSpec:
+			lintExportedValue(p, rule, gd, spec, kind, missing)
+		}
+	}
+}
+
+// lintExportedType will check the provided exported type.
+func lintExportedType(
+	p *lintPkg,
+	rule string,
+	gd *ast.GenDecl,
+	spec *ast.TypeSpec,
+	missing string,
+	stutter bool,
+) {
+	var doc *ast.CommentGroup = spec.Doc
+
+	if !spec.Name.IsExported() {
+		return
+	}
+
+	if (doc == nil) && !gd.Lparen.IsValid() {
+		doc = gd.Doc
+	}
+
+	if stutter {
+		lintStutter(p, rule, spec.Name, "type")
+	}
+
+	lintExportedDoc(
+		p,
+		rule,
+		spec,
+		doc,
+		"type",
+		spec.Name.Name,
+		missing,
+	)
+}
+
+// lintExportedValue will check the provided exported constants or
+// variables, once per spec.
+func lintExportedValue(
+	p *lintPkg,
+	rule string,
+	gd *ast.GenDecl,
+	spec *ast.ValueSpec,
+	kind string,
+	missing string,
+) {
+	var doc *ast.CommentGroup = spec.Doc
+	var grouped bool = gd.Lparen.IsValid()
+
+	// A comment on the block covers all of its values
+	if (doc == nil) && grouped && (gd.Doc != nil) {
+		return
+	} else if (doc == nil) && !grouped {
+		doc = gd.Doc
+	}
+
+	for _, id := range spec.Names {
+		if id.IsExported() {
+			lintExportedDoc(
+				p,
+				rule,
+				spec,
+				doc,
+				kind,
+				id.Name,
+				missing,
+			)
+			return
+		}
+	}
+}
+
+// lintFields will return the fields of the provided list, which may
+// be nil.
+func lintFields(fields *ast.FieldList) []*ast.Field {
+	if fields == nil {
+		return nil
+	}
+
+	return fields.List
+}
+
+// lintFuncTypes will call the provided function for the signature of
+// every function and function literal.
+func lintFuncTypes(p *lintPkg, fn func(ft *ast.FuncType)) {
+	lintInspect(
+		p,
+		func(lf *lintFile, n ast.Node) {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				fn(n.Type)
+			case *ast.FuncLit:
+				fn(n.Type)
+			}
+		},
+	)
+}
+
+// lintIncDec will complain about x += 1 and x -= 1.
+func lintIncDec(p *lintPkg, rule string, args []any) {
+	lintInspect(
+		p,
+		func(lf *lintFile, n ast.Node) {
+			var as *ast.AssignStmt
+			var ok bool
+			var op string
+
+			as, ok = n.(*ast.AssignStmt)
+			if !ok || (len(as.Lhs) != 1) || (len(as.Rhs) != 1) {
+				return
+			}
+
+			switch as.Tok {
+			case token.ADD_ASSIGN:
+				op = "++"
+			case token.SUB_ASSIGN:
+				op = "--"
+			default:
+				return
+			}
+
+			if lit, ok := as.Rhs[0].(*ast.BasicLit); ok {
+				if (lit.Kind == token.INT) && (lit.Value == "1") {
+					p.report(
+						rule,
+						0.8,
+						as,
+						"should replace %s %s 1 with %s%s",
+						types.ExprString(as.Lhs[0]),
+						as.Tok,
+						types.ExprString(as.Lhs[0]),
+						op,
+					)
+				}
+			}
+		},
+	)
+}
+
+// lintIndentErrorFlow will complain about else blocks following an
+// if block that ends with a return.
+func lintIndentErrorFlow(p *lintPkg, rule string, args []any) {
+	var chained map[*ast.IfStmt]bool = map[*ast.IfStmt]bool{}
+
+	lintInspect(
+		p,
+		func(lf *lintFile, n ast.Node) {
+			var msg string
+			var ok bool
+			var s *ast.IfStmt
+
+			if s, ok = n.(*ast.IfStmt); !ok {
+				return
+			}
+
+			// Else can't be dropped in the middle of a chain
+			if next, ok := s.Else.(*ast.IfStmt); ok {
+				chained[next] = true
+			}
+
+			if chained[s] || (len(s.Body.List) == 0) {
+				return
+			}
+
+			if _, ok = s.Else.(*ast.BlockStmt); !ok {
+				return
+			}
+
+			_, ok = s.Body.List[len(s.Body.List)-1].(*ast.ReturnStmt)
+			if !ok {
+				return
+			}
+
+			msg = "if block ends with a return statement, so drop" +
+				" this else and outdent its block"
+
+			if as, ok := s.Init.(*ast.AssignStmt); ok {
+				if as.Tok == token.DEFINE {
+					msg += " (move short variable declaration to" +
+						" its own line if necessary)"
+				}
+			}
+
+			p.report(rule, 1, s.Else, "%s", msg)
+		},
+	)
+}
+
+// lintInspect will call the provided function for every node in
+// every file of the provided package.
+func lintInspect(p *lintPkg, fn func(lf *lintFile, n ast.Node)) {
+	for _, lf := range p.files {
+		ast.Inspect(
+			lf.f,
+			func(n ast.Node) bool {
+				if n != nil {
+					fn(lf, n)
+				}
+
+				return true
+			},
+		)
+	}
+}
+
+// lintIsErrorCall will return whether or not the provided expression
+// creates a new error.
+func lintIsErrorCall(expr ast.Expr) bool {
+	var call *ast.CallExpr
+	var ok bool
+
+	if call, ok = expr.(*ast.CallExpr); !ok {
+		return false
+	}
+
+	return lintIsSelector(call.Fun, "errors", "New") ||
+		lintIsSelector(call.Fun, "fmt", "Errorf")
+}
+
+// lintIsIdent will return whether or not the provided expression is
+// the provided identifier.
+func lintIsIdent(expr ast.Expr, name string) bool {
+	var id *ast.Ident
+	var ok bool
+
+	id, ok = expr.(*ast.Ident)
+
+	return ok && (id.Name == name)
+}
+
+// lintIsSelector will return whether or not the provided expression
+// is pkg.name.
+func lintIsSelector(expr ast.Expr, pkg string, name string) bool {
+	var ok bool
+	var sel *ast.SelectorExpr
+
+	if sel, ok = expr.(*ast.SelectorExpr); !ok {
+		return false
+	}
+
+	return lintIsIdent(sel.X, pkg) && (sel.Sel.Name == name)
+}
+
+// lintIsTestFunc will return whether or not the provided name is
+// that of a test, benchmark, example, or fuzz function.
+func lintIsTestFunc(name string) bool {
+	for _, prefix := range []string{
+		"Benchmark", "Example", "Fuzz", "Test",
+	} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lintName will return the provided name with consistent initialisms
+// and without underscores, which may be unchanged.
+func lintName(name string, initialisms map[string]bool) string {
+	var eow bool
+	var i int
+	var runes []rune
+	var w int
+
+	// Only names with underscores or uppercase letters can change
+	if (name == "_") || !strings.ContainsFunc(name, lintNameRune) {
+		return name
+	}
+
+	runes = []rune(name)
+
+	// Split at lower to upper transitions and underscores
+	for i+1 <= len(runes) {
+		runes, eow = lintNameSplit(runes, i)
+		i++
+
+		if eow {
+			lintNameWord(runes, w, i, initialisms)
+			w = i
+		}
+	}
+
+	return string(runes)
+}
+
+// lintNameRune will return whether or not the provided rune could
+// make lintName change a name.
+func lintNameRune(r rune) bool {
+	return !unicode.IsLower(r) && !unicode.IsDigit(r)
+}
+
+// lintNameSplit will return whether or not the provided index is
+// the end of a word, with any underscores after it removed.
+func lintNameSplit(runes []rune, i int) ([]rune, bool) {
+	var n int = 1
+
+	if i+1 == len(runes) {
+		return runes, true
+	} else if runes[i+1] != '_' {
+		return runes, unicode.IsLower(runes[i]) &&
+			!unicode.IsLower(runes[i+1])
+	}
+
+	for (i+n+1 < len(runes)) && (runes[i+n+1] == '_') {
+		n++
+	}
+
+	// Keep one underscore between two digits
+	if (i+n+1 < len(runes)) && unicode.IsDigit(runes[i]) &&
+		unicode.IsDigit(runes[i+n+1]) {
+		n--
+	}
+
+	copy(runes[i+1:], runes[i+n+1:])
+
+	return runes[:len(runes)-n], true
+}
+
+// lintNameWord will fix the case of the word between the provided
+// indexes, if it is an initialism or not the first word.
+func lintNameWord(
+	runes []rune, w int, i int, initialisms map[string]bool,
+) {
+	var word string = string(runes[w:i])
+	var upper string = strings.ToUpper(word)
+
+	if initialisms[upper] {
+		// Only the first word may be all lowercase
+		if (w == 0) && unicode.IsLower(runes[w]) {
+			upper = strings.ToLower(upper)
+		}
+
+		copy(runes[w:], []rune(upper))
+	} else if (w > 0) && (strings.ToLower(word) == word) {
+		runes[w] = unicode.ToUpper(runes[w])
+	}
+}
+
+// lintPackageComments will complain about packages without a package
+// comment, and package comments not of the form "Package x ...".
+func lintPackageComments(p *lintPkg, rule string, args []any) {
+	var found bool
+	var prefix string = "Package " + p.name + " "
+
+	if strings.HasSuffix(p.name, "_test") {
+		return
+	}
+
+	for _, lf := range p.files {
+		if lf.test || (lf.f.Doc == nil) {
+			continue
+		}
+
+		found = true
+
+		if p.name == "main" {
+			continue
+		}
+
+		if !strings.HasPrefix(lf.f.Doc.Text(), prefix) {
+			p.report(
+				rule,
+				1,
+				lf.f.Doc,
+				"package comment should be of the form \"%s...\"",
+				prefix,
+			)
+		}
+	}
+
+	if !found && (len(p.files) > 0) {
+		p.report(
+			rule,
+			0.2,
+			p.files[0].f,
+			"should have a package comment",
+		)
+	}
+}
+
+// lintRange will complain about range loops with unneeded blank
+// variables.
+func lintRange(p *lintPkg, rule string, args []any) {
+	lintInspect(
+		p,
+		func(lf *lintFile, n ast.Node) {
+			var ok bool
+			var rs *ast.RangeStmt
+
+			rs, ok = n.(*ast.RangeStmt)
+			if !ok || (rs.Key == nil) {
+				return
+			}
+
+			if lintIsIdent(rs.Value, "_") {
+				p.report(
+					rule,
+					1,
+					rs.Value,
+					"should omit 2nd value from range; this loop is"+
+						" equivalent to `for %s %s range ...`",
+					types.ExprString(rs.Key),
+					rs.Tok,
+				)
+			} else if (rs.Value == nil) && lintIsIdent(rs.Key, "_") {
+				p.report(
+					rule,
+					1,
+					rs.Key,
+					"should omit values from range; this loop is"+
+						" equivalent to `for range ...`",
+				)
+			}
+		},
+	)
+}
+
+// lintReceiverNaming will complain about receivers named _, this, or
+// self, and receivers named differently for the same type.
+func lintReceiverNaming(p *lintPkg, rule string, args []any) {
+	var fd *ast.FuncDecl
+	var id *ast.Ident
+	var names map[string]string = map[string]string{}
+	var ok bool
+	var recv string
+
+	for _, lf := range p.files {
+		for _, decl := range lf.f.Decls {
+			fd, ok = decl.(*ast.FuncDecl)
+			if !ok || (fd.Recv == nil) || (len(fd.Recv.List) == 0) {
+				continue
+			}
+
+			if len(fd.Recv.List[0].Names) == 0 {
+				continue
+			}
+
+			id = fd.Recv.List[0].Names[0]
+			recv = lintRecvType(fd.Recv.List[0].Type)
+
+			switch id.Name {
+			case "_":
+				p.report(
+					rule,
+					1,
+					id,
+					"receiver name should not be an underscore, omit"+
+						" the name if it is unused",
+				)
+				continue
+			case "self", "this":
+				p.report(
+					rule,
+					1,
+					id,
+					"receiver name should be a reflection of its"+
+						" identity; don't use generic names such as"+
+						" \"this\" or \"self\"",
+				)
+				continue
+			}
+
+			if prev, ok := names[recv]; !ok {
+				names[recv] = id.Name
+			} else if prev != id.Name {
+				p.report(
+					rule,
+					1,
+					id,
+					"receiver name %s should be consistent with"+
+						" previous receiver name %s for %s",
+					id.Name,
+					prev,
+					recv,
+				)
+			}
+		}
+	}
+}
+
+// lintRecvType will return the name of the provided receiver type,
+// without pointers or type parameters.
+func lintRecvType(expr ast.Expr) string {
+	var recv string = complexityRecv(expr)
+
+	return strings.TrimLeft(recv, "*")
+}
+
+// lintResultLimit will complain about functions with more than the
+// configured number of results (default: 3).
+func lintResultLimit(p *lintPkg, rule string, args []any) {
+	var limit int = lintArgInt(args, 0, 3)
+
+	lintFuncTypes(
+		p,
+		func(ft *ast.FuncType) {
+			if n := lintCount(ft.Results); n > limit {
+				p.report(
+					rule,
+					1,
+					ft,
+					"maximum number of return results per function"+
+						" exceeded; max %d but got %d",
+					limit,
+					n,
+				)
+			}
+		},
+	)
+}
+
+// lintStutter will complain if the provided exported name starts
+// with the package name (e.g. pkg.PkgFoo).
+func lintStutter(
+	p *lintPkg, rule string, id *ast.Ident, kind string,
+) {
+	var r rune
+	var rem string
+
+	if len(id.Name) <= len(p.name) {
+		return
+	}
+
+	if !strings.HasPrefix(strings.ToLower(id.Name), p.name) {
+		return
+	}
+
+	rem = id.Name[len(p.name):]
+	if r, _ = utf8.DecodeRuneInString(rem); !unicode.IsUpper(r) {
+		return
+	}
+
+	p.report(
+		rule,
+		0.8,
+		id,
+		"%s name will be used as %s.%s by other packages, and that"+
+			" stutters; consider calling this %s",
+		kind,
+		p.name,
+		id.Name,
+		rem,
+	)
+}
+
+// lintTypeParams will return the names of the type parameters of
+// the provided function, which aren't declared types.
+func lintTypeParams(ft *ast.FuncType) map[string]bool {
+	var params map[string]bool = map[string]bool{}
+
+	for _, field := range lintFields(ft.TypeParams) {
+		for _, id := range field.Names {
+			params[id.Name] = true
+		}
+	}
+
+	return params
+}
+
+// lintUnexportedResults will complain if the provided exported
+// function returns unexported types.
+func lintUnexportedResults(
+	p *lintPkg, rule string, fd *ast.FuncDecl,
+) {
+	var kind string = "func"
+	var name string = fd.Name.Name
+	var params map[string]bool = lintTypeParams(fd.Type)
+	var recv string
+	var typ string
+
+	if (fd.Recv != nil) && (len(fd.Recv.List) > 0) {
+		kind = "method"
+		recv = lintRecvType(fd.Recv.List[0].Type)
+		name = recv + "." + name
+
+		if !ast.IsExported(recv) {
+			return
+		}
+	}
+
+	for _, field := range fd.Type.Results.List {
+		if typ = lintUnexportedType(field.Type, params); typ == "" {
+			continue
+		}
+
+		p.report(
+			rule,
+			0.8,
+			field,
+			"exported %s %s returns unexported type %s.%s,"+
+				" which can be annoying to use",
+			kind,
+			name,
+			p.name,
+			typ,
+		)
+	}
+}
+
+// lintUnexportedReturn will complain about exported functions which
+// return unexported types.
+func lintUnexportedReturn(p *lintPkg, rule string, args []any) {
+	var fd *ast.FuncDecl
+	var ok bool
+
+	if p.name == "main" {
+		return
+	}
+
+	for _, lf := range p.files {
+		if lf.test {
+			continue
+		}
+
+		for _, decl := range lf.f.Decls {
+			fd, ok = decl.(*ast.FuncDecl)
+			if !ok || !fd.Name.IsExported() {
+				continue
+			} else if fd.Type.Results != nil {
+				lintUnexportedResults(p, rule, fd)
+			}
+		}
+	}
+}
+
+// lintUnexportedType will return the name of the provided type, if
+// it, or what it points to, is an unexported declared type.
+func lintUnexportedType(typ ast.Expr, params map[string]bool) string {
+	var id *ast.Ident
+	var ok bool
+	var star *ast.StarExpr
+
+	if star, ok = typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+
+	if id, ok = typ.(*ast.Ident); !ok {
+		return ""
+	} else if id.IsExported() || params[id.Name] {
+		return ""
+	} else if slices.Contains(builtinTypes, id.Name) {
+		return ""
+	}
+
+	return id.Name
+}
+
+// lintVarName will complain if the provided name should be written
+// differently.
+func lintVarName(
+	p *lintPkg,
+	rule string,
+	initialisms map[string]bool,
+	id *ast.Ident,
+	kind string,
+) {
+	var should string
+
+	if id.Name == "_" {
+		return
+	}
+
+	if (len(id.Name) >= 5) && strings.Contains(id.Name, "_") &&
+		(strings.ToUpper(id.Name) == id.Name) {
+		p.report(
+			rule,
+			0.8,
+			id,
+			"don't use ALL_CAPS in Go names; use CamelCase",
+		)
+		return
+	}
+
+	if should = lintName(id.Name, initialisms); should == id.Name {
+		return
+	}
+
+	if strings.Contains(id.Name, "_") {
+		p.report(
+			rule,
+			0.9,
+			id,
+			"don't use underscores in Go names; %s %s should be %s",
+			kind,
+			id.Name,
+			should,
+		)
+		return
+	}
+
+	p.report(
+		rule,
+		0.8,
+		id,
+		"%s %s should be %s",
+		kind,
+		id.Name,
+		should,
+	)
+}
+
+// lintVarNaming will complain about names with underscores or
+// inconsistent initialisms (e.g. Id instead of ID). The first
+// argument is a list of initialisms to allow, and the second is a
+// list of additional initialisms, same as revive.
+func lintVarNaming(p *lintPkg, rule string, args []any) {
+	var check func(id *ast.Ident, kind string)
+	var initialisms map[string]bool = map[string]bool{}
+
+	for _, word := range commonInitialisms {
+		initialisms[word] = true
+	}
+
+	for _, word := range lintArgStrings(args, 0) {
+		delete(initialisms, strings.ToUpper(word))
+	}
+
+	for _, word := range lintArgStrings(args, 1) {
+		initialisms[strings.ToUpper(word)] = true
+	}
+
+	if strings.Contains(strings.TrimSuffix(p.name, "_test"), "_") {
+		p.report(
+			rule,
+			1,
+			p.files[0].f.Name,
+			"don't use an underscore in package name",
+		)
+	}
+
+	check = func(id *ast.Ident, kind string) {
+		lintVarName(p, rule, initialisms, id, kind)
+	}
+
+	lintInspect(
+		p,
+		func(lf *lintFile, n ast.Node) {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if n.Tok == token.DEFINE {
+					lintVarNamingExprs(n.Lhs, "var", check)
+				}
+			case *ast.FuncDecl:
+				lintVarNamingFunc(lf, n, check)
+			case *ast.FuncType:
+				lintVarNamingFields(n.Params, "func parameter", check)
+				lintVarNamingFields(n.Results, "func result", check)
+			case *ast.GenDecl:
+				lintVarNamingGen(n, check)
+			case *ast.InterfaceType:
+				lintVarNamingFields(
+					n.Methods,
+					"interface method",
+					check,
+				)
+			case *ast.RangeStmt:
+				if n.Tok == token.DEFINE {
+					lintVarNamingExprs(
+						[]ast.Expr{n.Key, n.Value},
+						"range var",
+						check,
+					)
+				}
+			case *ast.StructType:
+				lintVarNamingFields(n.Fields, "struct field", check)
+			}
+		},
+	)
+}
+
+// lintVarNamingExprs will check the names of the provided
+// expressions, which are identifiers when declaring variables.
+func lintVarNamingExprs(
+	exprs []ast.Expr, kind string, check func(*ast.Ident, string),
+) {
+	for _, expr := range exprs {
+		if id, ok := expr.(*ast.Ident); ok {
+			check(id, kind)
+		}
+	}
+}
+
+// lintVarNamingFields will check the names in the provided list of
+// fields.
+func lintVarNamingFields(
+	fields *ast.FieldList,
+	kind string,
+	check func(*ast.Ident, string),
+) {
+	for _, field := range lintFields(fields) {
+		for _, id := range field.Names {
+			check(id, kind)
+		}
+	}
+}
+
+// lintVarNamingFunc will check the name of the provided function or
+// method.
+func lintVarNamingFunc(
+	lf *lintFile, fd *ast.FuncDecl, check func(*ast.Ident, string),
+) {
+	// Test function names may contain underscores
+	if lf.test && lintIsTestFunc(fd.Name.Name) {
+		return
+	}
+
+	if fd.Recv == nil {
+		check(fd.Name, "func")
+	} else {
+		check(fd.Name, "method")
+	}
+}
+
+// lintVarNamingGen will check the names of the provided types,
+// constants, and variables.
+func lintVarNamingGen(
+	gd *ast.GenDecl, check func(*ast.Ident, string),
+) {
+	for _, spec := range gd.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			check(spec.Name, "type")
+		case *ast.ValueSpec:
+			for _, id := range spec.Names {
+				check(id, strings.ToLower(gd.Tok.String()))
+			}
+		}
+	}
+}
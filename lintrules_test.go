@@ -0,0 +1,367 @@
+package gocomplain
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLintErrorStrings(t *testing.T) {
+	var msg string = "error strings should not be capitalized or" +
+		" end with punctuation or a newline"
+	var tests []struct {
+		expected []string
+		name     string
+		src      string
+	} = []struct {
+		expected []string
+		name     string
+		src      string
+	}{
+		{
+			expected: []string{msg},
+			name:     "capitalized",
+			src:      `var e = errors.New("Bad thing")`,
+		},
+		{
+			expected: []string{msg},
+			name:     "punctuation",
+			src:      `var e = fmt.Errorf("bad thing: %d.", 1)`,
+		},
+		{
+			name: "initialism",
+			src:  `var e = errors.New("EOF reached")`,
+		},
+		{
+			name: "lowercase",
+			src:  `var e = errors.New("bad thing")`,
+		},
+		{
+			name: "other call",
+			src:  `var e = fmt.Sprintf("Bad thing.")`,
+		},
+	}
+
+	lintTest(t, "error-strings", tests)
+}
+
+func TestLintExported(t *testing.T) {
+	var tests []struct {
+		expected []string
+		name     string
+		src      string
+	} = []struct {
+		expected []string
+		name     string
+		src      string
+	}{
+		{
+			expected: []string{
+				"exported function A should have comment or be" +
+					" unexported",
+			},
+			name: "missing",
+			src:  "func A() {}",
+		},
+		{
+			expected: []string{
+				"comment on exported function A should be of the" +
+					" form \"A ...\"",
+			},
+			name: "form",
+			src:  "// does a thing\nfunc A() {}",
+		},
+		{
+			name: "documented",
+			src:  "// A will do a thing\nfunc A() {}",
+		},
+		{
+			name: "article",
+			src:  "// A T is a thing.\ntype T int",
+		},
+		{
+			name: "unexported",
+			src:  "func a() {}\n\ntype t int\n\nfunc (t) A() {}",
+		},
+		{
+			expected: []string{
+				"exported const A should have comment (or a" +
+					" comment on this block) or be unexported",
+			},
+			name: "grouped",
+			src:  "const (\n\tA = 1\n)",
+		},
+		{
+			name: "grouped documented",
+			src:  "// Values\nconst (\n\tA = 1\n\tB = 2\n)",
+		},
+		{
+			expected: []string{
+				"type name will be used as p.PFoo by other" +
+					" packages, and that stutters; consider" +
+					" calling this Foo",
+			},
+			name: "stutter",
+			src:  "// PFoo is a thing\ntype PFoo int",
+		},
+	}
+
+	lintTest(t, "exported", tests)
+}
+
+func TestLintName(t *testing.T) {
+	var initialisms map[string]bool = map[string]bool{
+		"API": true,
+		"ID":  true,
+	}
+	var tests map[string]string = map[string]string{
+		"_":        "_",
+		"a1_2":     "a1_2",
+		"Api_key":  "APIKey",
+		"foo":      "foo",
+		"foo__bar": "fooBar",
+		"foo_bar":  "fooBar",
+		"GetId":    "GetID",
+		"id":       "id",
+		"IdToName": "IDToName",
+		"userID":   "userID",
+		"user_id":  "userID",
+	}
+
+	for name, expected := range tests {
+		t.Run(
+			name,
+			func(t *testing.T) {
+				var actual string = lintName(name, initialisms)
+
+				if actual != expected {
+					t.Errorf("got %s, want %s", actual, expected)
+				}
+			},
+		)
+	}
+}
+
+func TestLintReceiverNaming(t *testing.T) {
+	var tests []struct {
+		expected []string
+		name     string
+		src      string
+	} = []struct {
+		expected []string
+		name     string
+		src      string
+	}{
+		{
+			expected: []string{
+				"receiver name should be a reflection of its" +
+					" identity; don't use generic names such as" +
+					" \"this\" or \"self\"",
+			},
+			name: "self",
+			src:  "func (self T) A() {}",
+		},
+		{
+			expected: []string{
+				"receiver name should not be an underscore, omit" +
+					" the name if it is unused",
+			},
+			name: "underscore",
+			src:  "func (_ T) A() {}",
+		},
+		{
+			expected: []string{
+				"receiver name b should be consistent with" +
+					" previous receiver name a for T",
+			},
+			name: "inconsistent",
+			src:  "func (a T) A() {}\n\nfunc (b *T) B() {}",
+		},
+		{
+			name: "consistent",
+			src:  "func (t T) A() {}\n\nfunc (t *T) B() {}",
+		},
+		{
+			name: "unnamed",
+			src:  "func (T) A() {}\n\nfunc (t T) B() {}",
+		},
+	}
+
+	lintTest(t, "receiver-naming", tests)
+}
+
+func TestLintUnexportedReturn(t *testing.T) {
+	var msg string = "exported func A returns unexported type p.t," +
+		" which can be annoying to use"
+	var tests []struct {
+		expected []string
+		name     string
+		src      string
+	} = []struct {
+		expected []string
+		name     string
+		src      string
+	}{
+		{
+			expected: []string{msg},
+			name:     "unexported",
+			src:      "func A() t { return 0 }",
+		},
+		{
+			expected: []string{msg},
+			name:     "pointer",
+			src:      "func A() (*t, error) { return nil, nil }",
+		},
+		{
+			expected: []string{
+				"exported method T.A returns unexported type p.t," +
+					" which can be annoying to use",
+			},
+			name: "method",
+			src:  "func (T) A() t { return 0 }",
+		},
+		{
+			name: "exported",
+			src:  "func A() T { return 0 }",
+		},
+		{
+			name: "builtin",
+			src:  "func A() error { return nil }",
+		},
+		{
+			name: "type parameter",
+			src:  "func A[t any]() (v t) { return }",
+		},
+		{
+			name: "unexported receiver",
+			src:  "func (t) A() t { return 0 }",
+		},
+		{
+			name: "unexported func",
+			src:  "func a() t { return 0 }",
+		},
+	}
+
+	lintTest(t, "unexported-return", tests)
+}
+
+func TestLintVarNaming(t *testing.T) {
+	var tests []struct {
+		expected []string
+		name     string
+		src      string
+	} = []struct {
+		expected []string
+		name     string
+		src      string
+	}{
+		{
+			expected: []string{
+				"don't use underscores in Go names; var foo_bar" +
+					" should be fooBar",
+			},
+			name: "snake case",
+			src:  "var foo_bar int",
+		},
+		{
+			expected: []string{"func GetId should be GetID"},
+			name:     "initialism",
+			src:      "func GetId() {}",
+		},
+		{
+			expected: []string{
+				"don't use ALL_CAPS in Go names; use CamelCase",
+			},
+			name: "all caps",
+			src:  "const MAX_SIZE = 1",
+		},
+		{
+			expected: []string{
+				"don't use underscores in Go names; func" +
+					" parameter user_name should be userName",
+				"don't use underscores in Go names; range var" +
+					" my_i should be myI",
+			},
+			name: "locals",
+			src: "func a(user_name []int) {\n" +
+				"\tfor my_i := range user_name {\n" +
+				"\t\t_ = my_i\n" +
+				"\t}\n" +
+				"}",
+		},
+		{
+			expected: []string{
+				"don't use underscores in Go names; struct field" +
+					" Api_key should be APIKey",
+			},
+			name: "struct field",
+			src:  "type T struct {\n\tApi_key string\n}",
+		},
+		{
+			name: "valid",
+			src: "var userID int\n\n" +
+				"func a(b string) (c int) {\n" +
+				"\td := 1\n" +
+				"\treturn d\n" +
+				"}",
+		},
+	}
+
+	lintTest(t, "var-naming", tests)
+}
+
+// lintTest will run the provided rule against the source of each
+// test, in package p, and compare the problems found.
+func lintTest(
+	t *testing.T,
+	rule string,
+	tests []struct {
+		expected []string
+		name     string
+		src      string
+	},
+) {
+	t.Helper()
+
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				var actual []string
+				var e error
+				var f *ast.File
+				var p *lintPkg = &lintPkg{
+					fset: token.NewFileSet(),
+					name: "p",
+				}
+
+				f, e = parser.ParseFile(
+					p.fset,
+					"a.go",
+					"package p\n\n"+test.src+"\n",
+					parser.ParseComments,
+				)
+				if e != nil {
+					t.Fatal(e)
+				}
+
+				p.files = []*lintFile{{f: f, fn: "a.go"}}
+				lintRules[rule].run(p, rule, nil)
+
+				for _, prob := range p.problems {
+					actual = append(
+						actual,
+						strings.TrimSuffix(prob.msg, " ("+rule+")"),
+					)
+				}
+
+				if !slices.Equal(actual, test.expected) {
+					t.Errorf("got %q, want %q", actual, test.expected)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,1156 @@
+package gocomplain
+
+import (
+	"bytes"
+	"cmp"
+	"embed"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	hl "github.com/mjwhitta/hilighter"
+	"golang.org/x/tools/go/packages"
+)
+
+// projectWord is a correctly spelled word from a project word list,
+// which applies to paths under the directory of the list, optionally
+// scoped to paths matching a glob.
+type projectWord struct {
+	dir  string
+	glob string
+	word string
+}
+
+// spellEdit is a replacement of old with text, at a byte offset.
+type spellEdit struct {
+	offset int
+	old    string
+	text   string
+}
+
+// spellFile is the original and current contents of a file being
+// fixed by SpellFix.
+type spellFile struct {
+	cur  []byte
+	mode fs.FileMode
+	orig []byte
+}
+
+// spellFixer will track the fixes chosen by SpellFix. Identifiers
+// map a file and byte offset to the declaration they refer to, which
+// is only renamed once (see seen).
+type spellFixer struct {
+	broken   map[string]bool
+	choose   func(f Finding, fixes []string) string
+	edits    map[string][]spellEdit
+	errs     []string
+	exported bool
+	files    map[string]*spellFile
+	idents   map[string]string
+	loaded   bool
+	objects  map[string]*spellObject
+	patterns []string
+	renames  map[string][]spellEdit
+	seen     map[string]bool
+}
+
+// spellObject is a declaration in the type-checked packages, and
+// every identifier that refers to it.
+type spellObject struct {
+	declared bool
+	obj      types.Object
+	uses     []spellUse
+}
+
+// spellUse is an identifier that refers to a spellObject.
+type spellUse struct {
+	fn     string
+	offset int
+	pkg    *packages.Package
+	pos    token.Pos
+}
+
+// spellWord is a word (or part of a compound word) to check, and
+// where to report it. Whole is the full word or identifier, which
+// starts at the byte offset, and text starts at "at" within it.
+type spellWord struct {
+	at     int
+	col    int
+	fixes  []string
+	ident  bool
+	line   int
+	offset int
+	text   string
+	whole  string
+}
+
+// Embedded dictionaries of common misspellings, in codespell format.
+// Codespell's own dictionaries are downloaded by "go generate".
+//
+//go:generate go run dict/codespell.go
+//go:embed dict/*.txt
+var dictionaries embed.FS
+
+var (
+	dictionary     map[string][]string
+	dictionaryOnce sync.Once
+)
+
+// SpellCheck will check the provided files for common misspellings,
+// using the embedded dictionaries, so it works the same on every OS.
+// Words listed in ignore are not reported (case-insensitive), nor
+// are words in the project word lists (.gocomplain-words). Files or
+// directories matching any of the skip globs are not checked. If no
+// files are provided, every file under the current directory is
+// checked.
+func SpellCheck(
+	ignore []string, skip []string, src ...map[string][]string,
+) []string {
+	var e error
+	var files []string
+	var found []spellWord
+	var out []string
+	var words []projectWord
+
+	files = spellFiles(skip, src...)
+
+	if ignore, words, e = spellSetup(ignore, files); e != nil {
+		out = append(out, e.Error())
+	}
+
+	for _, fn := range files {
+		if found, e = spellFind(fn, ignore, words); e != nil {
+			out = append(out, e.Error())
+			continue
+		}
+
+		for _, w := range found {
+			out = append(
+				out,
+				hl.Sprintf(
+					"%s:%d:%d: %s ==> %s",
+					fn,
+					w.line,
+					w.col,
+					w.text,
+					strings.Join(w.fixes, ", "),
+				),
+			)
+		}
+	}
+
+	return out
+}
+
+// SpellFix will fix the misspellings in the comments and strings of
+// the provided Go files, like SpellCheck. If there is more than one
+// possible fix, the provided choose func is asked which to use (an
+// empty string, or a nil func, leaves it alone). Misspelled
+// identifiers are renamed using the type-checked packages, so only
+// references to the same declaration are changed, and only if the
+// new name isn't already used there. Exported identifiers are only
+// renamed if exported is true. If the packages no longer build once
+// renamed, the renames are reverted. It returns the files that were
+// rewritten.
+func SpellFix(
+	ignore []string,
+	skip []string,
+	exported bool,
+	choose func(f Finding, fixes []string) string,
+	src ...map[string][]string,
+) []string {
+	var e error
+	var fixer *spellFixer = &spellFixer{
+		choose:   choose,
+		edits:    map[string][]spellEdit{},
+		exported: exported,
+		files:    map[string]*spellFile{},
+		idents:   map[string]string{},
+		objects:  map[string]*spellObject{},
+		patterns: spellPatterns(src...),
+		renames:  map[string][]spellEdit{},
+		seen:     map[string]bool{},
+	}
+	var files []string = spellFiles(skip, src...)
+	var found []spellWord
+	var out []string
+	var words []projectWord
+
+	if ignore, words, e = spellSetup(ignore, files); e != nil {
+		out = append(out, e.Error())
+	}
+
+	for _, fn := range files {
+		if !strings.HasSuffix(fn, ".go") {
+			continue
+		}
+
+		if found, e = spellFind(fn, ignore, words); e != nil {
+			out = append(out, e.Error())
+			continue
+		}
+
+		for _, w := range found {
+			fixer.word(fn, w)
+		}
+	}
+
+	out = append(out, fixer.errs...)
+	out = append(out, fixer.write(true)...)
+
+	if len(fixer.renames) > 0 {
+		if e = fixer.check(); e != nil {
+			out = append(out, e.Error())
+			out = append(out, fixer.write(false)...)
+		}
+	}
+
+	for _, fn := range slices.Sorted(maps.Keys(fixer.files)) {
+		if !bytes.Equal(fixer.files[fn].cur, fixer.files[fn].orig) {
+			out = append(out, fn)
+		}
+	}
+
+	return out
+}
+
+// readWords will return the words in the provided project word list.
+// Each line is a correctly spelled word, which is case-sensitive.
+// Comments start with "#". A "[glob]" line scopes the words that
+// follow it to paths (or parent directories) matching the glob,
+// relative to the directory of the list, until the next "[glob]"
+// line. "[*]" applies to every path again. A missing file is treated
+// as an empty list.
+func readWords(fn string) ([]projectWord, error) {
+	var b []byte
+	var dir string = filepath.Dir(fn)
+	var e error
+	var glob string = "*"
+	var out []projectWord
+
+	if b, e = os.ReadFile(fn); os.IsNotExist(e) {
+		return nil, nil
+	} else if e != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fn, e)
+	}
+
+	for _, line := range strings.Split(string(b), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		if section, ok := strings.CutPrefix(line, "["); ok {
+			section = strings.TrimSuffix(section, "]")
+			glob = strings.TrimSuffix(strings.TrimSpace(section), "/")
+			continue
+		}
+
+		for _, word := range strings.Fields(line) {
+			out = append(
+				out,
+				projectWord{dir: dir, glob: glob, word: word},
+			)
+		}
+	}
+
+	return out, nil
+}
+
+// spellCase will return the provided fixes with the same case as the
+// provided misspelled word (lowercase, capitalized, or uppercase).
+func spellCase(word string, fixes []string) []string {
+	var first rune
+	var out []string
+	var size int
+	var title bool
+
+	first, _ = utf8.DecodeRuneInString(word)
+	title = unicode.IsUpper(first)
+
+	for _, fix := range fixes {
+		first, size = utf8.DecodeRuneInString(fix)
+
+		if (len(word) > 1) && (strings.ToUpper(word) == word) {
+			fix = strings.ToUpper(fix)
+		} else if title {
+			fix = string(unicode.ToUpper(first)) + fix[size:]
+		}
+
+		out = append(out, fix)
+	}
+
+	return out
+}
+
+// spellDictionary will return the embedded dictionaries, mapping
+// lowercase misspellings to their fixes. They are only parsed once.
+func spellDictionary() map[string][]string {
+	dictionaryOnce.Do(
+		func() {
+			var b []byte
+			var fns []string
+
+			dictionary = map[string][]string{}
+			fns, _ = fs.Glob(dictionaries, "dict/*.txt")
+
+			for _, fn := range fns {
+				b, _ = dictionaries.ReadFile(fn)
+				spellParse(string(b))
+			}
+		},
+	)
+
+	return dictionary
+}
+
+// spellFiles will return the provided files that aren't skipped, or
+// every file under the current directory if none are provided.
+func spellFiles(skip []string, src ...map[string][]string) []string {
+	var files []string
+
+	skip = append(slices.Clone(skip), spellSkip...)
+
+	if len(src) == 0 {
+		files = spellWalk(".", skip)
+	}
+
+	for i := range src {
+		for dir, fns := range src[i] {
+			for _, fn := range fns {
+				fn = filepath.Join(dir, fn)
+
+				if !spellSkipped(fn, skip) {
+					files = append(files, fn)
+				}
+			}
+		}
+	}
+
+	slices.Sort(files)
+
+	return files
+}
+
+// spellFind will return the misspelled words in the provided file,
+// with their fixes. Binary files are skipped and Go files are
+// tokenized, so misspellings within identifiers are found.
+func spellFind(
+	fn string, ignore []string, words []projectWord,
+) ([]spellWord, error) {
+	var b []byte
+	var e error
+	var found []spellWord
+	var out []spellWord
+
+	if b, e = os.ReadFile(fn); e != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fn, e)
+	}
+
+	if bytes.IndexByte(b, 0) >= 0 {
+		return nil, nil
+	}
+
+	if strings.HasSuffix(fn, ".go") {
+		found = spellGo(fn, b)
+	} else {
+		found = spellText(
+			string(b),
+			token.Position{Column: 1, Line: 1},
+		)
+	}
+
+	for _, w := range found {
+		if spellIgnored(fn, w, ignore, words) {
+			continue
+		}
+
+		w.fixes = spellDictionary()[strings.ToLower(w.text)]
+		if len(w.fixes) == 0 {
+			continue
+		}
+
+		w.fixes = spellCase(w.text, w.fixes)
+		out = append(out, w)
+	}
+
+	return out, nil
+}
+
+// spellGo will return the words in the identifiers, comments, and
+// string literals of the provided Go source. Compound identifiers
+// (camelCase or snake_case) are split, but each part is positioned at
+// the start of the identifier. Import paths are not checked.
+func spellGo(fn string, b []byte) []spellWord {
+	var f *token.File
+	var imports bool
+	var lit string
+	var out []spellWord
+	var paren bool
+	var pos token.Position
+	var s scanner.Scanner
+	var tok token.Token
+	var tp token.Pos
+
+	f = token.NewFileSet().AddFile(fn, -1, len(b))
+	s.Init(f, b, nil, scanner.ScanComments)
+
+	for {
+		if tp, tok, lit = s.Scan(); tok == token.EOF {
+			break
+		}
+
+		pos = f.Position(tp)
+
+		switch tok {
+		case token.COMMENT:
+			out = append(out, spellText(lit, pos)...)
+		case token.IDENT:
+			for _, w := range spellText(lit, pos) {
+				w.at += w.offset - pos.Offset
+				w.col = pos.Column
+				w.ident = true
+				w.offset = pos.Offset
+				w.whole = lit
+				out = append(out, w)
+			}
+		case token.IMPORT:
+			imports = true
+		case token.LPAREN:
+			paren = imports
+		case token.RPAREN:
+			imports = false
+			paren = false
+		case token.SEMICOLON:
+			imports = imports && paren
+		case token.STRING:
+			if imports {
+				continue
+			}
+
+			// Escapes would run into the following word
+			lit = goEscapes.ReplaceAllStringFunc(
+				lit,
+				func(esc string) string {
+					return strings.Repeat(" ", len(esc))
+				},
+			)
+
+			out = append(out, spellText(lit, pos)...)
+		}
+	}
+
+	return out
+}
+
+// spellIgnored will return whether or not the provided word (or the
+// whole word or identifier it is part of) is ignored, either by the
+// provided lowercase ignore list or by the project word list.
+func spellIgnored(
+	fn string, w spellWord, ignore []string, words []projectWord,
+) bool {
+	for _, str := range []string{w.text, w.whole} {
+		if slices.Contains(ignore, strings.ToLower(str)) {
+			return true
+		}
+	}
+
+	for _, pw := range words {
+		if (pw.word != w.text) && (pw.word != w.whole) {
+			continue
+		}
+
+		if spellScoped(fn, pw.dir, pw.glob) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// spellLoad will type-check the packages in the provided
+// directories, and return them with their errors.
+func spellLoad(
+	patterns []string,
+) ([]*packages.Package, map[string]bool, error) {
+	var cfg *packages.Config = &packages.Config{
+		Mode:  packages.LoadAllSyntax,
+		Tests: true,
+	}
+	var e error
+	var errs map[string]bool = map[string]bool{}
+	var pkgs []*packages.Package
+
+	if pkgs, e = packages.Load(cfg, patterns...); e != nil {
+		return nil, nil, fmt.Errorf("failed to load packages: %w", e)
+	}
+
+	packages.Visit(
+		pkgs,
+		nil,
+		func(pkg *packages.Package) {
+			for _, pe := range pkg.Errors {
+				errs[pe.Error()] = true
+			}
+		},
+	)
+
+	return pkgs, errs, nil
+}
+
+// spellMember will return whether or not the provided type already
+// has a field or method with the provided name.
+func spellMember(t types.Type, pkg *types.Package, name string) bool {
+	var obj types.Object
+
+	obj, _, _ = types.LookupFieldOrMethod(t, true, pkg, name)
+
+	return obj != nil
+}
+
+// spellParse will add the provided codespell dictionary to the
+// dictionary. Each line is "typo->fix[, fix...]". Like codespell, if
+// there is more than one fix and no trailing comma, the last entry is
+// a reason rather than a fix.
+func spellParse(dict string) {
+	var fixes []string
+	var ok bool
+	var typo string
+
+	for _, line := range strings.Split(dict, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		typo, line, ok = strings.Cut(strings.TrimSpace(line), "->")
+		if !ok {
+			continue
+		}
+
+		fixes = strings.Split(line, ",")
+		if (len(fixes) > 1) && !strings.HasSuffix(line, ",") {
+			fixes = fixes[:len(fixes)-1]
+		}
+
+		typo = strings.ToLower(strings.TrimSpace(typo))
+
+		for _, fix := range fixes {
+			fix = strings.TrimSpace(fix)
+
+			if (fix == "") || slices.Contains(dictionary[typo], fix) {
+				continue
+			}
+
+			dictionary[typo] = append(dictionary[typo], fix)
+		}
+	}
+}
+
+// spellPatterns will return the directories of the provided Go
+// files, or of every Go file under the current directory if none are
+// provided, as absolute paths to load.
+func spellPatterns(src ...map[string][]string) []string {
+	var abs string
+	var out []string
+
+	if len(src) == 0 {
+		src = make([]map[string][]string, 2)
+		src[0], src[1], _ = FindSrcFiles(".")
+	}
+
+	for i := range src {
+		for dir, fns := range src[i] {
+			for _, fn := range fns {
+				if !strings.HasSuffix(fn, ".go") {
+					continue
+				}
+
+				if abs, _ = filepath.Abs(dir); abs != "" {
+					out = append(out, abs)
+				}
+
+				break
+			}
+		}
+	}
+
+	slices.Sort(out)
+
+	return slices.Compact(out)
+}
+
+// spellRel will return the provided path relative to the current
+// directory, if possible, so each file has one name.
+func spellRel(fn string) string {
+	var abs string
+	var cwd string
+	var e error
+	var rel string
+
+	if abs, e = filepath.Abs(fn); e != nil {
+		return fn
+	} else if cwd, e = os.Getwd(); e != nil {
+		return abs
+	}
+
+	rel, e = filepath.Rel(cwd, abs)
+	if (e != nil) || strings.HasPrefix(rel, "..") {
+		return abs
+	}
+
+	return rel
+}
+
+// spellScoped will return whether or not the provided path is under
+// the provided directory, and whether it, its name, or a parent
+// directory (relative to the provided directory) matches the
+// provided glob.
+func spellScoped(fn string, dir string, glob string) bool {
+	var e error
+
+	if fn, e = filepath.Abs(fn); e != nil {
+		return false
+	} else if dir, e = filepath.Abs(dir); e != nil {
+		return false
+	} else if fn, e = filepath.Rel(dir, fn); e != nil {
+		return false
+	}
+
+	if fn = filepath.ToSlash(fn); strings.HasPrefix(fn, "../") {
+		return false
+	} else if fn == ".." {
+		return false
+	} else if glob == "*" {
+		return true
+	} else if ok, _ := path.Match(glob, path.Base(fn)); ok {
+		return true
+	}
+
+	for fn != "." {
+		if ok, _ := path.Match(glob, fn); ok {
+			return true
+		}
+
+		fn = path.Dir(fn)
+	}
+
+	return false
+}
+
+// spellSetup will return the provided ignore list in lowercase, and
+// the words in the project word lists of the current directory and
+// of each directory containing the provided files, up to the current
+// directory.
+func spellSetup(
+	ignore []string, files []string,
+) ([]string, []projectWord, error) {
+	var dir string
+	var dirs map[string]bool = map[string]bool{".": true}
+	var e error
+	var errs []error
+	var found []projectWord
+	var out []string
+	var words []projectWord
+
+	for _, word := range ignore {
+		out = append(out, strings.ToLower(word))
+	}
+
+	for _, fn := range files {
+		if filepath.IsAbs(fn) {
+			continue
+		}
+
+		// Each parent directory, up to "." which is always included
+		for dir = filepath.Dir(fn); !dirs[dir]; {
+			dirs[dir] = true
+			dir = filepath.Dir(dir)
+		}
+	}
+
+	for _, dir = range slices.Sorted(maps.Keys(dirs)) {
+		found, e = readWords(filepath.Join(dir, wordsFile))
+		if e != nil {
+			errs = append(errs, e)
+		}
+
+		words = append(words, found...)
+	}
+
+	return out, words, errors.Join(errs...)
+}
+
+// spellSkipped will return whether or not the provided path, or its
+// name, matches any of the provided globs.
+func spellSkipped(fn string, skip []string) bool {
+	for _, glob := range skip {
+		if ok, _ := filepath.Match(glob, filepath.Base(fn)); ok {
+			return true
+		} else if ok, _ = filepath.Match(glob, fn); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// spellSplit will return the byte ranges of the parts of the provided
+// camelCase word (e.g. parseHTTPServer is parse, HTTP, and Server).
+func spellSplit(word string) [][2]int {
+	var lower bool
+	var offsets []int
+	var out [][2]int
+	var prev rune
+	var runes []rune
+	var start int
+	var upper bool
+
+	for i, r := range word {
+		offsets = append(offsets, i)
+		runes = append(runes, r)
+	}
+
+	offsets = append(offsets, len(word))
+
+	for i := 1; i < len(runes); i++ {
+		lower = unicode.IsLower(runes[i])
+		prev = runes[i-1]
+		upper = unicode.IsUpper(runes[i])
+
+		if upper && unicode.IsLower(prev) {
+			// Lower to upper starts a new part (e.g. parseHTTP)
+			out = append(out, [2]int{offsets[start], offsets[i]})
+			start = i
+		} else if lower && unicode.IsUpper(prev) && (i-1 > start) {
+			// Upper to lower ends an acronym (e.g. HTTPServer)
+			out = append(out, [2]int{offsets[start], offsets[i-1]})
+			start = i - 1
+		}
+	}
+
+	return append(out, [2]int{offsets[start], len(word)})
+}
+
+// spellText will return the words in the provided text, which starts
+// at the provided position. Compound words are split.
+func spellText(text string, pos token.Position) []spellWord {
+	var col int = pos.Column
+	var offset int = pos.Offset
+	var out []spellWord
+
+	for i, ln := range strings.Split(text, "\n") {
+		if i > 0 {
+			col = 1
+		}
+
+		for _, m := range spellWords.FindAllStringIndex(ln, -1) {
+			for _, part := range spellSplit(ln[m[0]:m[1]]) {
+				out = append(
+					out,
+					spellWord{
+						at:     part[0],
+						col:    col + m[0] + part[0],
+						line:   pos.Line + i,
+						offset: offset + m[0],
+						text:   ln[m[0]+part[0] : m[0]+part[1]],
+						whole:  ln[m[0]:m[1]],
+					},
+				)
+			}
+		}
+
+		offset += len(ln) + 1
+	}
+
+	return out
+}
+
+// spellWalk will return every file under the provided directory that
+// isn't skipped.
+func spellWalk(root string, skip []string) []string {
+	var out []string
+
+	_ = filepath.WalkDir(
+		root,
+		func(fn string, d fs.DirEntry, e error) error {
+			if e != nil {
+				return nil
+			}
+
+			if (fn != root) && spellSkipped(fn, skip) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if !d.IsDir() {
+				out = append(out, fn)
+			}
+
+			return nil
+		},
+	)
+
+	return out
+}
+
+// apply will rewrite the provided file from its original contents,
+// with the provided edits applied from the end, so earlier offsets
+// are still valid. Edits that overlap, or don't match the file, are
+// skipped.
+func (f *spellFixer) apply(fn string, edits []spellEdit) error {
+	var b []byte
+	var e error
+	var end int
+	var info os.FileInfo
+	var last int
+	var sf *spellFile = f.files[fn]
+
+	if sf == nil {
+		if info, e = os.Stat(fn); e != nil {
+			return fmt.Errorf("failed to read %s: %w", fn, e)
+		}
+
+		if b, e = os.ReadFile(fn); e != nil {
+			return fmt.Errorf("failed to read %s: %w", fn, e)
+		}
+
+		sf = &spellFile{cur: b, mode: info.Mode().Perm(), orig: b}
+		f.files[fn] = sf
+	}
+
+	slices.SortFunc(
+		edits,
+		func(a spellEdit, b spellEdit) int {
+			return cmp.Compare(b.offset, a.offset)
+		},
+	)
+
+	b = sf.orig
+	last = len(b)
+
+	for _, edit := range edits {
+		if end = edit.offset + len(edit.old); end > last {
+			continue
+		} else if string(b[edit.offset:end]) != edit.old {
+			continue
+		}
+
+		b = slices.Concat(b[:edit.offset], []byte(edit.text), b[end:])
+		last = edit.offset
+	}
+
+	if bytes.Equal(b, sf.cur) {
+		return nil
+	}
+
+	if e = os.WriteFile(fn, b, sf.mode); e != nil {
+		return fmt.Errorf("failed to write %s: %w", fn, e)
+	}
+
+	sf.cur = b
+
+	return nil
+}
+
+// check will return an error if the packages have any new errors,
+// now that identifiers are renamed.
+func (f *spellFixer) check() error {
+	var e error
+	var errs map[string]bool
+	var msgs []string
+
+	if _, errs, e = spellLoad(f.patterns); e != nil {
+		return fmt.Errorf("failed to rename identifiers: %w", e)
+	}
+
+	for msg := range errs {
+		if !f.broken[msg] {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	slices.Sort(msgs)
+
+	return fmt.Errorf(
+		"failed to rename identifiers, reverted: %s",
+		msgs[0],
+	)
+}
+
+// index will record that the provided identifier refers to the
+// provided object.
+func (f *spellFixer) index(
+	pkg *packages.Package, id *ast.Ident, obj types.Object, def bool,
+) {
+	var key string
+	var o *spellObject
+	var pos token.Position
+
+	if (obj == nil) || (obj.Pkg() == nil) {
+		return
+	}
+
+	// Line directives would move positions to other files
+	pos = pkg.Fset.PositionFor(obj.Pos(), false)
+	key = pos.Filename + ":" + strconv.Itoa(pos.Offset)
+
+	if o = f.objects[key]; o == nil {
+		o = &spellObject{obj: obj}
+		f.objects[key] = o
+	}
+
+	o.declared = o.declared || (def && (id.Pos() == obj.Pos()))
+
+	pos = pkg.Fset.PositionFor(id.Pos(), false)
+	pos.Filename = spellRel(pos.Filename)
+
+	f.idents[pos.Filename+":"+strconv.Itoa(pos.Offset)] = key
+	o.uses = append(
+		o.uses,
+		spellUse{
+			fn:     pos.Filename,
+			offset: pos.Offset,
+			pkg:    pkg,
+			pos:    id.Pos(),
+		},
+	)
+}
+
+// load will type-check the packages, and record the object each
+// identifier refers to, along with any existing errors.
+func (f *spellFixer) load() {
+	var e error
+	var pkgs []*packages.Package
+
+	f.loaded = true
+
+	if pkgs, f.broken, e = spellLoad(f.patterns); e != nil {
+		f.errs = append(f.errs, e.Error())
+		return
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+
+		for id, obj := range pkg.TypesInfo.Defs {
+			f.index(pkg, id, obj, true)
+		}
+
+		for id, obj := range pkg.TypesInfo.Uses {
+			f.index(pkg, id, obj, false)
+		}
+	}
+}
+
+// pick will return the fix to use for the provided misspelled word,
+// asking the choose func if there is more than one.
+func (f *spellFixer) pick(fn string, w spellWord) string {
+	if len(w.fixes) == 1 {
+		return w.fixes[0]
+	} else if f.choose == nil {
+		return ""
+	}
+
+	return f.choose(
+		Finding{
+			Col:  w.col,
+			File: fn,
+			Line: w.line,
+			Msg: hl.Sprintf(
+				"%s ==> %s",
+				w.text,
+				strings.Join(w.fixes, ", "),
+			),
+			Tool: "spellcheck",
+		},
+		w.fixes,
+	)
+}
+
+// word will record the fix for the provided misspelled word. Words in
+// comments and strings are fixed in place, while identifiers are
+// renamed everywhere their declaration is referenced. Each
+// declaration is only considered once, and exported identifiers only
+// if enabled.
+func (f *spellFixer) word(fn string, w spellWord) {
+	var fix string
+	var key string
+	var name string
+	var o *spellObject
+
+	fn = spellRel(fn)
+
+	if !w.ident {
+		if fix = f.pick(fn, w); fix == "" {
+			return
+		}
+
+		f.edits[fn] = append(
+			f.edits[fn],
+			spellEdit{
+				offset: w.offset + w.at,
+				old:    w.text,
+				text:   fix,
+			},
+		)
+
+		return
+	}
+
+	if token.IsExported(w.whole) && !f.exported {
+		return
+	}
+
+	if !f.loaded {
+		f.load()
+	}
+
+	key = f.idents[fn+":"+strconv.Itoa(w.offset)]
+	if (key == "") || f.seen[key] {
+		return
+	}
+
+	f.seen[key] = true
+
+	// Only rename declarations in the provided packages
+	if o = f.objects[key]; !o.declared {
+		return
+	}
+
+	if fix = f.pick(fn, w); fix == "" {
+		return
+	}
+
+	name = w.whole[:w.at] + fix + w.whole[w.at+len(w.text):]
+	if !token.IsIdentifier(name) || o.conflicts(name) {
+		return
+	}
+
+	for _, use := range o.uses {
+		f.renames[use.fn] = append(
+			f.renames[use.fn],
+			spellEdit{offset: use.offset, old: w.whole, text: name},
+		)
+	}
+}
+
+// write will rewrite each file with its fixes, and with renamed
+// identifiers if enabled. It returns any errors.
+func (f *spellFixer) write(renames bool) []string {
+	var edits []spellEdit
+	var fns []string
+	var out []string
+
+	fns = slices.Concat(
+		slices.Collect(maps.Keys(f.edits)),
+		slices.Collect(maps.Keys(f.renames)),
+	)
+	slices.Sort(fns)
+
+	for _, fn := range slices.Compact(fns) {
+		edits = slices.Clone(f.edits[fn])
+
+		if renames {
+			edits = append(edits, f.renames[fn]...)
+		}
+
+		if e := f.apply(fn, edits); e != nil {
+			out = append(out, e.Error())
+		}
+	}
+
+	return out
+}
+
+// conflicts will return whether or not the provided name is already
+// used where the object is declared or referenced, as a field or
+// method of the same type, or in scope within its own package.
+func (o *spellObject) conflicts(name string) bool {
+	var other types.Object
+	var parent *types.Scope = o.obj.Parent()
+	var scope *types.Scope
+
+	switch obj := o.obj.(type) {
+	case *types.Func:
+		if recv := obj.Signature().Recv(); recv != nil {
+			return spellMember(recv.Type(), obj.Pkg(), name)
+		}
+	case *types.Var:
+		if obj.IsField() {
+			return o.field(name)
+		}
+	}
+
+	if (parent != nil) && (parent.Lookup(name) != nil) {
+		return true
+	}
+
+	for _, use := range o.uses {
+		if use.pkg.PkgPath != o.obj.Pkg().Path() {
+			continue
+		}
+
+		scope = use.pkg.Types.Scope().Innermost(use.pos)
+		if scope == nil {
+			continue
+		}
+
+		_, other = scope.LookupParent(name, use.pos)
+		if other != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// field will return whether or not a named struct type, that the
+// field belongs to, already has a field or method with the provided
+// name. Fields of unnamed structs are left to the build check.
+func (o *spellObject) field(name string) bool {
+	var scope *types.Scope = o.obj.Pkg().Scope()
+	var st *types.Struct
+	var tn *types.TypeName
+
+	for _, n := range scope.Names() {
+		if tn, _ = scope.Lookup(n).(*types.TypeName); tn == nil {
+			continue
+		}
+
+		if st, _ = tn.Type().Underlying().(*types.Struct); st == nil {
+			continue
+		}
+
+		for i := range st.NumFields() {
+			if st.Field(i) == o.obj {
+				return spellMember(tn.Type(), tn.Pkg(), name)
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,378 @@
+package gocomplain
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSpellCheck(t *testing.T) {
+	var out []string
+	var tests []struct {
+		expected []string
+		ignore   []string
+		name     string
+		src      string
+	} = []struct {
+		expected []string
+		ignore   []string
+		name     string
+		src      string
+	}{
+		{
+			expected: []string{
+				"a.go:3:8: mesage ==> message, massage",
+			},
+			name: "ambiguous",
+			src:  "package a\n\n// The mesage.\nfunc a() {}\n",
+		},
+		{
+			expected: []string{
+				"a.go:3:13: teh ==> the",
+				"a.go:4:6: recieve ==> receive",
+			},
+			name: "fragments",
+			src: "package a\n\n" +
+				"// Send it, teh.\nfunc recieveAll() {}\n",
+		},
+		{
+			ignore: []string{"Teh"},
+			name:   "ignored",
+			src:    "package a\n\n// teh\nfunc a() {}\n",
+		},
+		{
+			expected: []string{`a.go:3:15: adress ==> address`},
+			name:     "strings",
+			src:      "package a\n\nvar a = \"an \\tadress\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				t.Chdir(t.TempDir())
+				spellWrite(t, "a.go", test.src)
+
+				out = SpellCheck(
+					test.ignore,
+					nil,
+					map[string][]string{".": {"a.go"}},
+				)
+
+				if !slices.Equal(out, test.expected) {
+					t.Errorf("got %q, want %q", out, test.expected)
+				}
+			},
+		)
+	}
+}
+
+func TestSpellDictionary(t *testing.T) {
+	var dict map[string][]string = spellDictionary()
+	var multi int
+
+	for typo, fixes := range dict {
+		if len(fixes) == 0 {
+			t.Errorf("%s has no fixes", typo)
+		} else if len(fixes) > 1 {
+			multi++
+		}
+
+		if slices.Contains(fixes, typo) {
+			t.Errorf("%s is its own fix", typo)
+		}
+	}
+
+	// Ambiguous misspellings need more than one fix
+	if multi == 0 {
+		t.Error("no misspellings with more than one fix")
+	}
+}
+
+func TestSpellFixChoose(t *testing.T) {
+	var asked [][]string
+	var b []byte
+	var choose func(f Finding, fixes []string) string
+	var e error
+	var tests []struct {
+		choice   int
+		expected string
+		name     string
+	} = []struct {
+		choice   int
+		expected string
+		name     string
+	}{
+		{choice: -1, expected: "// Send the mesage.", name: "skip"},
+		{choice: 1, expected: "// Send the massage.", name: "chosen"},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				t.Chdir(t.TempDir())
+				spellWrite(
+					t,
+					"a.go",
+					"package a\n\n// Send teh mesage.\nfunc a() {}\n",
+				)
+
+				asked = nil
+				choose = func(f Finding, fixes []string) string {
+					asked = append(asked, fixes)
+
+					if test.choice < 0 {
+						return ""
+					}
+
+					return fixes[test.choice]
+				}
+
+				SpellFix(
+					nil,
+					nil,
+					false,
+					choose,
+					map[string][]string{".": {"a.go"}},
+				)
+
+				// Only the ambiguous misspelling is asked about
+				if len(asked) != 1 {
+					t.Fatalf("asked %d times, want 1", len(asked))
+				}
+
+				if !slices.Equal(
+					asked[0],
+					[]string{"message", "massage"},
+				) {
+					t.Errorf("got fixes %q", asked[0])
+				}
+
+				if b, e = os.ReadFile("a.go"); e != nil {
+					t.Fatal(e)
+				}
+
+				if !strings.Contains(string(b), test.expected) {
+					t.Errorf("got %q, want %q", b, test.expected)
+				}
+			},
+		)
+	}
+}
+
+func TestSpellFixRename(t *testing.T) {
+	var b []byte
+	var choose func(f Finding, fixes []string) string
+	var e error
+	var out []string
+	var tests []struct {
+		expected string
+		name     string
+		reverted bool
+		src      string
+	} = []struct {
+		expected string
+		name     string
+		reverted bool
+		src      string
+	}{
+		{
+			expected: "package a\n\n" +
+				"type t struct{ message int }\n\n" +
+				"type u struct{ mesage int }\n\n" +
+				"func (x t) get() int { return x.message }\n\n" +
+				"func (y u) get() int { return y.mesage }\n",
+			name: "fields",
+			src: "package a\n\n" +
+				"type t struct{ mesage int }\n\n" +
+				"type u struct{ mesage int }\n\n" +
+				"func (x t) get() int { return x.mesage }\n\n" +
+				"func (y u) get() int { return y.mesage }\n",
+		},
+		{
+			expected: "package a\n\n" +
+				"func a() int {\n" +
+				"\tvar message int = 1\n\n" +
+				"\treturn message + b()\n" +
+				"}\n\n" +
+				"func b() int {\n" +
+				"\tvar mesage int = 2\n\n" +
+				"\treturn mesage\n" +
+				"}\n",
+			name: "locals",
+			src: "package a\n\n" +
+				"func a() int {\n" +
+				"\tvar mesage int = 1\n\n" +
+				"\treturn mesage + b()\n" +
+				"}\n\n" +
+				"func b() int {\n" +
+				"\tvar mesage int = 2\n\n" +
+				"\treturn mesage\n" +
+				"}\n",
+		},
+		{
+			expected: "package a\n\n" +
+				"// The message.\n" +
+				"var a = struct{ mesage int }{}\n\n" +
+				"var b struct{ mesage int } = a\n",
+			name:     "reverted",
+			reverted: true,
+			src: "package a\n\n" +
+				"// The mesage.\n" +
+				"var a = struct{ mesage int }{}\n\n" +
+				"var b struct{ mesage int } = a\n",
+		},
+	}
+
+	// Only fix the misspellings before line 5
+	choose = func(f Finding, fixes []string) string {
+		if f.Line < 5 {
+			return fixes[0]
+		}
+
+		return ""
+	}
+
+	for _, test := range tests {
+		t.Run(
+			test.name,
+			func(t *testing.T) {
+				t.Chdir(t.TempDir())
+				spellWrite(t, "go.mod", "module a\n\ngo 1.22\n")
+				spellWrite(t, "a.go", test.src)
+
+				out = SpellFix(nil, nil, false, choose)
+
+				if b, e = os.ReadFile("a.go"); e != nil {
+					t.Fatal(e)
+				}
+
+				if string(b) != test.expected {
+					t.Errorf("got %q, want %q", b, test.expected)
+				}
+
+				if test.reverted != slices.ContainsFunc(
+					out,
+					func(line string) bool {
+						return strings.Contains(line, "reverted")
+					},
+				) {
+					t.Errorf("got %q", out)
+				}
+			},
+		)
+	}
+}
+
+func TestSpellScoped(t *testing.T) {
+	var tests []struct {
+		dir      string
+		expected bool
+		fn       string
+		glob     string
+	} = []struct {
+		dir      string
+		expected bool
+		fn       string
+		glob     string
+	}{
+		{dir: ".", expected: true, fn: "a/b.go", glob: "*"},
+		{dir: ".", expected: true, fn: "a/b.go", glob: "a"},
+		{dir: ".", expected: true, fn: "a/b.go", glob: "*.go"},
+		{dir: ".", expected: false, fn: "a/b.go", glob: "b"},
+		{dir: "a", expected: true, fn: "a/b/c.go", glob: "b"},
+		{dir: "a", expected: false, fn: "a/b/c.go", glob: "a"},
+		{dir: "a", expected: false, fn: "b/c.go", glob: "*"},
+		{dir: "a", expected: false, fn: "ab/c.go", glob: "*"},
+		{dir: "a", expected: false, fn: "c.go", glob: "*"},
+	}
+
+	for _, test := range tests {
+		t.Run(
+			path.Join(test.dir, test.glob)+" "+test.fn,
+			func(t *testing.T) {
+				var actual bool = spellScoped(
+					test.fn,
+					test.dir,
+					test.glob,
+				)
+
+				if actual != test.expected {
+					t.Errorf(
+						"got %t, want %t",
+						actual,
+						test.expected,
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestSpellWords(t *testing.T) {
+	var src string = "package a\n\n// teh recieve adress mesage\n"
+	var tests map[string][]string = map[string][]string{
+		"a.go":     {"recieve", "adress", "mesage"},
+		"a/a.go":   {"recieve", "adress"},
+		"a/c/c.go": {"recieve"},
+		"b/b.go":   {"adress", "mesage"},
+		"c/c.go":   {"recieve", "adress", "mesage"},
+	}
+	var words map[string]string = map[string]string{
+		".gocomplain-words": "# Not mesage\nteh\n\n[b]\nrecieve\n",
+		"a/.gocomplain-words": "[c]\nadress # Only under a/c\n\n" +
+			"[*]\nmesage\n",
+		"c/.gocomplain-words": "Mesage\n",
+	}
+
+	t.Chdir(t.TempDir())
+
+	for _, dir := range []string{"a/c", "b", "c"} {
+		if e := os.MkdirAll(dir, 0o700); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	for fn, list := range words {
+		spellWrite(t, fn, list)
+	}
+
+	for fn, expected := range tests {
+		spellWrite(t, fn, src)
+
+		t.Run(
+			fn,
+			func(t *testing.T) {
+				var actual []string
+
+				for _, line := range SpellCheck(
+					nil,
+					nil,
+					map[string][]string{
+						filepath.Dir(fn): {filepath.Base(fn)},
+					},
+				) {
+					actual = append(actual, strings.Fields(line)[1])
+				}
+
+				if !slices.Equal(actual, expected) {
+					t.Errorf("got %q, want %q", actual, expected)
+				}
+			},
+		)
+	}
+}
+
+// spellWrite will write the provided test file.
+func spellWrite(t *testing.T, fn string, src string) {
+	t.Helper()
+
+	if e := os.WriteFile(fn, []byte(src), 0o600); e != nil {
+		t.Fatal(e)
+	}
+}
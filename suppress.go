@@ -0,0 +1,242 @@
+package gocomplain
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// suppressFile is a file as read when checking findings for inline
+// ignore directives. It is reused until the file changes.
+type suppressFile struct {
+	generated bool
+	lines     []string
+	mod       time.Time
+	size      int64
+}
+
+// Files already read by Ignored, keyed by path
+var suppressed map[string]*suppressFile = map[string]*suppressFile{}
+var suppressedMutex sync.Mutex
+
+// BaselineAdd will append the provided Finding to the provided
+// baseline file, so that it is no longer reported.
+func BaselineAdd(fn string, f Finding) error {
+	var e error
+	var file *os.File
+
+	file, e = os.OpenFile(
+		fn,
+		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+		0o644,
+	)
+	if e != nil {
+		return fmt.Errorf("failed to open %s: %w", fn, e)
+	}
+	defer file.Close()
+
+	_, e = fmt.Fprintf(
+		file,
+		"%s\t%s\t%s\n",
+		f.Tool,
+		filepath.ToSlash(f.File),
+		f.Msg,
+	)
+	if e != nil {
+		return fmt.Errorf("failed to write %s: %w", fn, e)
+	}
+
+	return nil
+}
+
+// IgnoreInline will insert an ignore directive above the line of the
+// provided Finding. Only Go source files are supported.
+func IgnoreInline(f Finding) error {
+	var b []byte
+	var e error
+	var indent string
+	var info os.FileInfo
+	var lines []string
+
+	if !strings.HasSuffix(f.File, ".go") || (f.Line < 1) {
+		return fmt.Errorf("can't ignore inline in %s", f.File)
+	}
+
+	if info, e = os.Stat(f.File); e != nil {
+		return fmt.Errorf("failed to read %s: %w", f.File, e)
+	}
+
+	if b, e = os.ReadFile(f.File); e != nil {
+		return fmt.Errorf("failed to read %s: %w", f.File, e)
+	}
+
+	if lines = strings.Split(string(b), "\n"); f.Line > len(lines) {
+		return fmt.Errorf("%s has no line %d", f.File, f.Line)
+	}
+
+	indent = lines[f.Line-1]
+	indent = indent[:len(indent)-len(strings.TrimLeft(indent, " \t"))]
+
+	lines = slices.Insert(
+		lines,
+		f.Line-1,
+		indent+ignoreDirective+" "+f.Tool,
+	)
+
+	e = os.WriteFile(
+		f.File,
+		[]byte(strings.Join(lines, "\n")),
+		info.Mode().Perm(),
+	)
+	if e != nil {
+		return fmt.Errorf("failed to write %s: %w", f.File, e)
+	}
+
+	return nil
+}
+
+// ReadBaseline will return the findings listed in the provided
+// baseline file. A missing file is treated as an empty baseline.
+func ReadBaseline(fn string) ([]Finding, error) {
+	var e error
+	var f *os.File
+	var found []Finding
+	var s *bufio.Scanner
+	var tmp []string
+
+	if f, e = os.Open(fn); os.IsNotExist(e) {
+		return nil, nil
+	} else if e != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", fn, e)
+	}
+	defer f.Close()
+
+	s = bufio.NewScanner(f)
+	for s.Scan() {
+		if strings.HasPrefix(s.Text(), "#") {
+			continue
+		}
+
+		if tmp = strings.SplitN(s.Text(), "\t", 3); len(tmp) != 3 {
+			continue
+		}
+
+		found = append(
+			found,
+			Finding{
+				File: filepath.FromSlash(tmp[1]),
+				Msg:  tmp[2],
+				Tool: tmp[0],
+			},
+		)
+	}
+
+	if e = s.Err(); e != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fn, e)
+	}
+
+	return found, nil
+}
+
+// Ignored will return whether or not the Finding is listed in the
+// provided baseline, is in a generated Go file, or has been ignored
+// inline with a "//gocomplain:ignore [tool...]" comment on or above
+// its line.
+func (f Finding) Ignored(baseline []Finding) bool {
+	var file *suppressFile
+	var lines []string
+
+	for _, ignore := range baseline {
+		if (ignore.Tool == f.Tool) &&
+			(ignore.File == f.File) &&
+			(ignore.Msg == f.Msg) {
+			return true
+		}
+	}
+
+	if (f.File == "") || (f.Line < 1) {
+		return false
+	}
+
+	if file = suppressRead(f.File); file == nil {
+		return false
+	} else if file.generated {
+		return true
+	}
+
+	if lines = file.lines; f.Line > len(lines) {
+		return false
+	}
+
+	if f.ignoredBy(lines[f.Line-1], false) {
+		return true
+	}
+
+	return (f.Line > 1) && f.ignoredBy(lines[f.Line-2], true)
+}
+
+// suppressRead will return the provided file, split into lines, or
+// nil if it can't be read. Files are only read again if they have
+// changed, since there are often many findings per file.
+func suppressRead(fn string) *suppressFile {
+	var b []byte
+	var e error
+	var file *suppressFile
+	var info os.FileInfo
+	var ok bool
+
+	if info, e = os.Stat(fn); e != nil {
+		return nil
+	}
+
+	suppressedMutex.Lock()
+	defer suppressedMutex.Unlock()
+
+	file, ok = suppressed[fn]
+	if ok && (file.size == info.Size()) &&
+		file.mod.Equal(info.ModTime()) {
+		return file
+	}
+
+	if b, _ = os.ReadFile(fn); len(b) == 0 {
+		delete(suppressed, fn)
+		return nil
+	}
+
+	file = &suppressFile{
+		generated: strings.HasSuffix(fn, ".go") && isGenerated(fn, b),
+		lines:     strings.Split(string(b), "\n"),
+		mod:       info.ModTime(),
+		size:      info.Size(),
+	}
+	suppressed[fn] = file
+
+	return file
+}
+
+func (f Finding) ignoredBy(line string, above bool) bool {
+	var directive string
+	var found bool
+
+	line = strings.TrimSpace(line)
+
+	if above && !strings.HasPrefix(line, ignoreDirective) {
+		return false
+	}
+
+	_, directive, found = strings.Cut(line, ignoreDirective)
+	if !found {
+		return false
+	}
+
+	if directive = strings.TrimSpace(directive); directive == "" {
+		return true
+	}
+
+	return slices.Contains(strings.Fields(directive), f.Tool)
+}
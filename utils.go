@@ -3,14 +3,40 @@ package gocomplain
 import (
 	"bytes"
 	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/mjwhitta/log"
 )
 
+// buildContext will return the build context used by the go command
+// for the provided GOOS/GOARCH pair and comma-separated build tags.
+func buildContext(target string, tags string) build.Context {
+	var ctx build.Context = build.Default
+	var native bool
+
+	ctx.GOOS, ctx.GOARCH, _ = strings.Cut(target, "/")
+	native = (ctx.GOOS == runtime.GOOS) &&
+		(ctx.GOARCH == runtime.GOARCH)
+
+	// Cross-compiling disables cgo unless explicitly enabled
+	ctx.CgoEnabled = CGO || (native && build.Default.CgoEnabled)
+
+	if tags != "" {
+		ctx.BuildTags = strings.Split(tags, ",")
+	}
+
+	return ctx
+}
+
 func execute(cmd []string) (string, error) {
 	var b []byte
 	var e error
@@ -45,6 +71,44 @@ func info(str string) {
 	}
 }
 
+// isGenerated will return whether or not the provided Go source has
+// a "Code generated ... DO NOT EDIT." comment, per the Go convention.
+func isGenerated(fn string, src []byte) bool {
+	var f *ast.File
+
+	f, _ = parser.ParseFile(
+		token.NewFileSet(),
+		fn,
+		src,
+		parser.PackageClauseOnly|parser.ParseComments,
+	)
+
+	return (f != nil) && ast.IsGenerated(f)
+}
+
+// matchAny will return whether or not the provided file is included
+// by any of the provided build contexts.
+func matchAny(
+	ctxs []build.Context, dir string, fn string,
+) (bool, error) {
+	var e error
+	var match bool
+
+	for _, ctx := range ctxs {
+		if match, e = ctx.MatchFile(dir, fn); e != nil {
+			return false, fmt.Errorf(
+				"failed to read %s: %w",
+				filepath.Join(dir, fn),
+				e,
+			)
+		} else if match {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func run(cmd []string) []string {
 	var cwd string
 	var e error
@@ -82,6 +146,38 @@ func run(cmd []string) []string {
 	return out
 }
 
+// runEach will run the provided command once per directory, with the
+// directory's files appended. If no files are provided, the command
+// is run once with the provided default target, if any.
+func runEach(
+	cmd []string, target string, src ...map[string][]string,
+) []string {
+	var out []string
+	var tmp []string
+
+	if len(src) == 0 {
+		if target == "" {
+			return run(cmd)
+		}
+
+		return run(slices.Concat(cmd, []string{target}))
+	}
+
+	for i := range src {
+		for dir, files := range src[i] {
+			tmp = slices.Clone(cmd)
+
+			for _, file := range files {
+				tmp = append(tmp, filepath.Join(dir, file))
+			}
+
+			out = append(out, run(tmp)...)
+		}
+	}
+
+	return out
+}
+
 func subInfof(str string, args ...any) {
 	if !Quiet {
 		log.SubInfof(str, args...)
@@ -0,0 +1,129 @@
+package gocomplain
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch will monitor the provided directory for changes, using the
+// same pruning rules as FindSrcFiles. Bursts of events are debounced
+// and the provided handler is called with the files that changed,
+// grouped by directory. Watch only returns on error.
+func Watch(
+	search string,
+	settle time.Duration,
+	handler func(changed map[string][]string),
+	prune ...string,
+) error {
+	var changed map[string][]string = map[string][]string{}
+	var e error
+	var timer <-chan time.Time
+	var w *fsnotify.Watcher
+
+	if w, e = fsnotify.NewWatcher(); e != nil {
+		return fmt.Errorf("failed to create watcher: %w", e)
+	}
+	defer w.Close()
+
+	if e = watchDirs(w, search, prune...); e != nil {
+		return e
+	}
+
+	for {
+		select {
+		case evt, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+
+			if !watchEvent(w, evt, changed, prune...) {
+				continue
+			}
+
+			timer = time.After(settle)
+		case e, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+
+			return fmt.Errorf("failed to watch %s: %w", search, e)
+		case <-timer:
+			timer = nil
+
+			handler(changed)
+			changed = map[string][]string{}
+		}
+	}
+}
+
+func watchDirs(
+	w *fsnotify.Watcher, search string, prune ...string,
+) error {
+	return filepath.WalkDir(
+		search,
+		func(fn string, d fs.DirEntry, e error) error {
+			if e != nil {
+				return nil
+			}
+
+			if !d.IsDir() {
+				return nil
+			}
+
+			if (fn != search) && watchPruned(d.Name(), prune...) {
+				return filepath.SkipDir
+			}
+
+			if e = w.Add(fn); e != nil {
+				return fmt.Errorf("failed to watch %s: %w", fn, e)
+			}
+
+			return nil
+		},
+	)
+}
+
+func watchEvent(
+	w *fsnotify.Watcher,
+	evt fsnotify.Event,
+	changed map[string][]string,
+	prune ...string,
+) bool {
+	var dir string = filepath.Dir(evt.Name)
+	var fn string = filepath.Base(evt.Name)
+	var info os.FileInfo
+
+	if evt.Has(fsnotify.Chmod) && !evt.Has(fsnotify.Write) {
+		return false
+	}
+
+	if watchPruned(fn, prune...) || alwaysIgnore.MatchString(fn) {
+		return false
+	}
+
+	// Newly created directories need to be watched too
+	if evt.Has(fsnotify.Create) {
+		info, _ = os.Stat(evt.Name)
+
+		if (info != nil) && info.IsDir() {
+			_ = watchDirs(w, evt.Name, prune...)
+			return false
+		}
+	}
+
+	if !slices.Contains(changed[dir], fn) {
+		changed[dir] = append(changed[dir], fn)
+	}
+
+	return true
+}
+
+func watchPruned(name string, prune ...string) bool {
+	return (name == ".git") || slices.Contains(prune, name)
+}